@@ -0,0 +1,225 @@
+package ygrpcgoutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ConverterFunc converts a value to another type, used by WithConverter
+type ConverterFunc func(value interface{}) (interface{}, error)
+
+// TagKeyConvert is the field tag naming a RegisterConverter converter applied to the value
+// before it is set, like `convert:"upper"`. only used by mappers created WithTagDirectives
+const TagKeyConvert = "convert"
+
+// TagKeyScale is the field tag giving the decimal places a number is formatted with when set
+// into a string field, like `scale:"2"` sets 12.5 as "12.50". only used by mappers created WithTagDirectives
+const TagKeyScale = "scale"
+
+var (
+	namedConvertersMu sync.RWMutex
+	namedConverters   = map[string]ConverterFunc{
+		"upper": stringConverter(strings.ToUpper),
+		"lower": stringConverter(strings.ToLower),
+		"trim":  stringConverter(strings.TrimSpace),
+	}
+)
+
+// RegisterConverter register a converter by name for the `convert:"name"` field tag,
+// "upper", "lower" and "trim" are registered by default
+func RegisterConverter(name string, converter ConverterFunc) {
+	namedConvertersMu.Lock()
+	defer namedConvertersMu.Unlock()
+	namedConverters[name] = converter
+}
+
+func namedConverter(name string) (ConverterFunc, bool) {
+	namedConvertersMu.RLock()
+	defer namedConvertersMu.RUnlock()
+	converter, ok := namedConverters[name]
+	return converter, ok
+}
+
+func stringConverter(fn func(string) string) ConverterFunc {
+	return func(value interface{}) (interface{}, error) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("converter needs a string, got %T", value)
+		}
+		return fn(s), nil
+	}
+}
+
+type converterKey struct {
+	from reflect.Type
+	to   reflect.Type
+}
+
+// Mapper holds the configuration of field get/set/copy, different subsystems
+// can use different mappers instead of sharing package globals.
+// the package level functions(SetField, CopyFields...) use a default Mapper
+type Mapper struct {
+	tagKey     string
+	strict     bool
+	converters map[converterKey]ConverterFunc
+	logger     func(format string, args ...interface{})
+
+	tagDirectives      bool
+	skipZero           bool
+	copyReferenceTypes bool
+
+	//parseStrings parses numeric and bool text into number and bool fields, used by SetFieldsFromEnv
+	parseStrings bool
+}
+
+// MapperOption configures a Mapper
+type MapperOption func(*Mapper)
+
+var defaultMapper = NewMapper()
+
+// NewMapper create a Mapper, without options it behaves like the package level functions
+func NewMapper(opts ...MapperOption) *Mapper {
+	m := &Mapper{
+		converters: make(map[converterKey]ConverterFunc),
+		logger: func(format string, args ...interface{}) {
+			fmt.Printf(format+"\n", args...)
+		},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// WithTagKey set the tag key used to match fields, Copy matches fields by tag name,
+// Items uses tag names as keys, Get/Set accept a tag name when no field has that name
+func WithTagKey(tagKey string) MapperOption {
+	return func(m *Mapper) {
+		m.tagKey = tagKey
+	}
+}
+
+// WithStrictNumbers make numeric conversions fail when the value can not be
+// represented by the target type(overflow, fractional float to int) instead of wrapping silently,
+// negative values into unsigned types fail in any mode
+func WithStrictNumbers() MapperOption {
+	return func(m *Mapper) {
+		m.strict = true
+	}
+}
+
+// WithConverter register a converter used when setting a from typed value into a to typed field,
+// the converter takes precedence over the built in conversions
+func WithConverter(from, to reflect.Type, converter ConverterFunc) MapperOption {
+	return func(m *Mapper) {
+		m.converters[converterKey{from: from, to: to}] = converter
+	}
+}
+
+// WithTagDirectives enable field tags that change how a value is set, like `convert:"upper"` and `scale:"2"`
+func WithTagDirectives() MapperOption {
+	return func(m *Mapper) {
+		m.tagDirectives = true
+	}
+}
+
+// WithSkipZero make Set/SetFields leave the field unchanged when the value is a zero value,
+// for patch semantics. note nil and nil pointers are zero values too, so they are skipped
+// instead of clearing pointer fields
+func WithSkipZero() MapperOption {
+	return func(m *Mapper) {
+		m.skipZero = true
+	}
+}
+
+// WithCopyReferenceTypes make Set/SetFields/Copy deep copy slice, map and pointer values before setting
+// them, following elements, pointer targets and exported struct fields, so the struct doesn't share data
+// with the caller's value. unexported struct fields are still shared. by default everything is shared
+// for performance. only Mapper methods copy, the package level SetField/CopyFields always share
+func WithCopyReferenceTypes() MapperOption {
+	return func(m *Mapper) {
+		m.copyReferenceTypes = true
+	}
+}
+
+// WithLogger set the logger for conversion warnings, nil disables logging
+func WithLogger(logger func(format string, args ...interface{})) MapperOption {
+	return func(m *Mapper) {
+		m.logger = logger
+	}
+}
+
+func (m *Mapper) logf(format string, args ...interface{}) {
+	if m.logger != nil {
+		m.logger(format, args...)
+	}
+}
+
+// fieldName resolve name to the go field name of obj, when the mapper has a tag key
+// and obj has no field called name, the field whose tag name is name is used
+func (m *Mapper) fieldName(obj interface{}, name string) string {
+	if m.tagKey == "" || name == "" {
+		return name
+	}
+	if ok, _ := HasField(obj, name); ok {
+		return name
+	}
+	tags, err := structFieldNamesAndTags(obj, m.tagKey, true, false, nil)
+	if err != nil {
+		return name
+	}
+	if fieldName, ok := tags[name]; ok {
+		return fieldName
+	}
+	return name
+}
+
+// Get returns the value of the obj field like GetField
+func (m *Mapper) Get(obj interface{}, name string) (interface{}, error) {
+	return GetField(obj, m.fieldName(obj, name))
+}
+
+// Set sets the obj field with value like SetField using the mapper configuration
+func (m *Mapper) Set(obj interface{}, name string, value interface{}) error {
+	return m.setField(obj, m.fieldName(obj, name), value)
+}
+
+// Copy copies src fields into dst like CopyFields, fields are matched by tag name
+// when the mapper has a tag key
+func (m *Mapper) Copy(dst, src interface{}) error {
+	if m.tagKey == "" {
+		return m.copyFields(dst, src)
+	}
+	_, err := m.copyFieldsByTags(dst, src, m.tagKey, m.tagKey)
+	return err
+}
+
+// Items returns the flattened field - value pairs like ItemsDeep, keys are tag names
+// when the mapper has a tag key(field name for untagged fields, "-" tagged fields are skipped)
+func (m *Mapper) Items(obj interface{}) (map[string]interface{}, error) {
+	allItems, err := ItemsDeep(obj)
+	if err != nil || m.tagKey == "" {
+		return allItems, err
+	}
+
+	tags, err := structFieldNamesAndTags(obj, m.tagKey, true, true, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tagItems := make(map[string]interface{}, len(allItems))
+	for fieldName, value := range allItems {
+		key := tags[fieldName]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = fieldName
+		}
+		tagItems[key] = value
+	}
+
+	return tagItems, nil
+}
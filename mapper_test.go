@@ -0,0 +1,80 @@
+package ygrpcgoutil
+
+import (
+	"testing"
+)
+
+func TestCopyReferenceTypes(t *testing.T) {
+	type item struct {
+		Tags []string
+	}
+	type holder struct {
+		Items []item
+		IDs   *[]int
+		Ptr   *item
+	}
+
+	m := NewMapper(WithCopyReferenceTypes())
+
+	items := []item{{Tags: []string{"a"}}}
+	ids := []int{1}
+	ptr := &item{Tags: []string{"p"}}
+	var h holder
+	if err := m.Set(&h, "Items", items); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set(&h, "IDs", ids); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set(&h, "Ptr", ptr); err != nil {
+		t.Fatal(err)
+	}
+
+	items[0].Tags[0] = "changed"
+	ids[0] = 2
+	ptr.Tags[0] = "changed"
+	if h.Items[0].Tags[0] != "a" {
+		t.Errorf("slice inside struct element shared: %v", h.Items[0].Tags)
+	}
+	if (*h.IDs)[0] != 1 {
+		t.Errorf("slice behind allocated pointer shared: %v", *h.IDs)
+	}
+	if h.Ptr == ptr || h.Ptr.Tags[0] != "p" {
+		t.Errorf("pointer target shared: %v", h.Ptr.Tags)
+	}
+
+	type node struct {
+		Next *node
+	}
+	cycle := &node{}
+	cycle.Next = cycle
+	var n struct{ Head *node }
+	if err := m.Set(&n, "Head", cycle); err != nil {
+		t.Fatal(err)
+	}
+	if n.Head == cycle || n.Head.Next != n.Head {
+		t.Errorf("cyclic pointer not copied as a cycle")
+	}
+}
+
+func TestCopyReferenceTypesSharedAddress(t *testing.T) {
+	type inner struct {
+		N int
+	}
+	type pair struct {
+		A *struct{}
+		B *[0]int
+		S *inner
+		N *int
+	}
+
+	in := &inner{N: 1}
+	var h struct{ V pair }
+	m := NewMapper(WithCopyReferenceTypes())
+	if err := m.Set(&h, "V", pair{A: &struct{}{}, B: &[0]int{}, S: in, N: &in.N}); err != nil {
+		t.Fatal(err)
+	}
+	if h.V.S == in || h.V.S.N != 1 || *h.V.N != 1 {
+		t.Errorf("copy = %+v", h.V)
+	}
+}
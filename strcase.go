@@ -0,0 +1,74 @@
+package ygrpcgoutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ToSnakeCase converts a go field name to snake_case, UserID => user_id, HTTPServer => http_server
+func ToSnakeCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// ToCamelCase converts a name to lowerCamelCase, user_id => userId, UserID => userID
+// all upper case words (acronyms) are kept as is except the first one
+func ToCamelCase(s string) string {
+	words := splitWords(s)
+	var sb strings.Builder
+	for i, w := range words {
+		if i == 0 {
+			sb.WriteString(strings.ToLower(w))
+			continue
+		}
+		if strings.ToUpper(w) == w {
+			sb.WriteString(w)
+			continue
+		}
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// splitWords split s into words by separators(_ - space) and case changes,
+// an upper case run is treated as one word(acronym): UserIDList => User ID List
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		if unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
@@ -0,0 +1,53 @@
+package ygrpcgoutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeRange is the half open time range [Start, End)
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewTimeRange create a TimeRange, return error when end is before start
+func NewTimeRange(start, end time.Time) (TimeRange, error) {
+	if end.Before(start) {
+		return TimeRange{}, fmt.Errorf("invalid time range: end %s is before start %s", GetUtcTimeStr(end), GetUtcTimeStr(start))
+	}
+	return TimeRange{Start: start, End: end}, nil
+}
+
+// Contains reports whether Start <= t < End
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// Overlaps reports whether r and other share any instant, ranges only touching at
+// one's End and the other's Start do not overlap
+func (r TimeRange) Overlaps(other TimeRange) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// Duration return End - Start
+func (r TimeRange) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// BucketStart return the start of the window long bucket containing t, buckets are aligned to origin
+// (origin, origin+window, ... and before it origin-window, ...), like billing periods starting at an epoch.
+// the result is in origin's location, window must be > 0
+func BucketStart(t time.Time, window time.Duration, origin time.Time) (time.Time, error) {
+	if window <= 0 {
+		return time.Time{}, fmt.Errorf("invalid bucket window %s, must be > 0", window)
+	}
+
+	n := t.Sub(origin) / window
+	start := origin.Add(n * window)
+	if start.After(t) {
+		//t before origin, truncation went towards origin
+		start = start.Add(-window)
+	}
+	return start.In(origin.Location()), nil
+}
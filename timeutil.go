@@ -1,39 +1,147 @@
 package ygrpcgoutil
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
 // ISOTimeFormat iso time format yyyy-mm-dd HH:MM:SS
 const ISOTimeFormat = "2006-01-02 15:04:05"
 const ISOTimeFormatzzz = "2006-01-02 15:04:05.000"
 
+// DateOnlyFormat is the layout of yyyy-mm-dd
+const DateOnlyFormat = "2006-01-02"
+
+// Now returns the current time for the Now* helpers, GetNowUnixEpochInMilliseconds
+// and TimeSince/TimeUntil, tests can replace it(or use SetClock) to freeze the clock
+var Now = time.Now
+
+// SetClock replaces the Now clock, like func() time.Time { return fixed } in tests, nil restores time.Now.
+// it is not goroutine safe, don't change the clock while the helpers are in use
+func SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	Now = clock
+}
+
+// ResetClock restores the Now clock to time.Now, like SetClock it is not goroutine safe
+func ResetClock() {
+	Now = time.Now
+}
+
 // NowTimeStrInLocal return yyyy-mm-dd hh:mm:ss in local time
 func NowTimeStrInLocal() string {
-	t := time.Now()
+	t := Now()
 	return t.Format(ISOTimeFormat)
 }
 
 // NowTimeStrInUtc return yyyy-mm-dd hh:mm:ss in utc time
 func NowTimeStrInUtc() string {
-	t := time.Now().UTC()
+	t := Now().UTC()
 	return t.Format(ISOTimeFormat)
 }
 
 // NowTimeStrInUtcZzz return yyyy-mm-dd hh:mm:ss.zzz in utc time
 func NowTimeStrInUtcZzz() string {
-	t := time.Now().UTC()
+	t := Now().UTC()
 	return t.Format(ISOTimeFormatzzz)
 }
 
+// NowTimeStrIn return yyyy-mm-dd hh:mm:ss in loc, nil loc means utc
+func NowTimeStrIn(loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t := Now().In(loc)
+	return t.Format(ISOTimeFormat)
+}
+
+// NowTimeStrInZone return yyyy-mm-dd hh:mm:ss in the IANA zone like "Asia/Shanghai"
+func NowTimeStrInZone(zoneName string) (string, error) {
+	loc, err := time.LoadLocation(zoneName)
+	if err != nil {
+		return "", err
+	}
+	return NowTimeStrIn(loc), nil
+}
+
 func TimeISOStr(t time.Time) string {
 	return t.Format(ISOTimeFormat)
 }
 
+var (
+	isoLayoutMu sync.RWMutex
+	isoLayout   = ISOTimeFormat
+)
+
+// SetISOTimeFormat overrides the layout used by FormatISO/ParseISO and by SetField/StructCSVRow
+// when formatting time.Time as string, like "2006-01-02T15:04:05". empty layout restores ISOTimeFormat
+func SetISOTimeFormat(layout string) {
+	if layout == "" {
+		layout = ISOTimeFormat
+	}
+	isoLayoutMu.Lock()
+	isoLayout = layout
+	isoLayoutMu.Unlock()
+}
+
+// ISOTimeLayout returns the layout set by SetISOTimeFormat, ISOTimeFormat by default
+func ISOTimeLayout() string {
+	isoLayoutMu.RLock()
+	defer isoLayoutMu.RUnlock()
+	return isoLayout
+}
+
+// FormatISO format t with the ISOTimeLayout
+func FormatISO(t time.Time) string {
+	return t.Format(ISOTimeLayout())
+}
+
+// ParseISO parse s with the ISOTimeLayout, times without zone are utc.
+// the parse error is returned, ErrEmptyTime for an empty s
+func ParseISO(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, ErrEmptyTime
+	}
+	layout := ISOTimeLayout()
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q as %s: %w", s, layout, err)
+	}
+	return t, nil
+}
+
 func GetUnixEpochInMilliseconds(t time.Time) int64 {
 	return t.UnixNano() / int64(time.Millisecond)
 }
 
 func GetNowUnixEpochInMilliseconds() int64 {
-	return time.Now().UnixNano() / int64(time.Millisecond)
+	return Now().UnixNano() / int64(time.Millisecond)
+}
+
+// TimeSince returns the time elapsed since t by the Now clock
+func TimeSince(t time.Time) time.Duration {
+	return TimeSinceAt(Now(), t)
+}
+
+// TimeUntil returns the duration until t by the Now clock
+func TimeUntil(t time.Time) time.Duration {
+	return TimeUntilAt(Now(), t)
+}
+
+// TimeSinceAt returns the time elapsed since t at now
+func TimeSinceAt(now, t time.Time) time.Duration {
+	return now.Sub(t)
+}
+
+// TimeUntilAt returns the duration until t at now
+func TimeUntilAt(now, t time.Time) time.Duration {
+	return t.Sub(now)
 }
 
 // get utc time format yyyy-mm-dd HH:MM:SS of time
@@ -46,6 +154,20 @@ func GetUtcTimeStrzzz(t time.Time) string {
 	return t.UTC().Format(ISOTimeFormatzzz)
 }
 
+// ErrEmptyTime is returned by the error returning parsers(ParseISOFlexible, ParseDateOnly)
+// for an empty string, so "no time given" can be told apart from a malformed time
+var ErrEmptyTime = errors.New("empty time string")
+
+// EmptyTime returns time.Time{}, for code that means "no time" explicitly
+func EmptyTime() time.Time {
+	return time.Time{}
+}
+
+// IsZeroTime reports whether t is the zero time.Time, which ParseUTCTime also returns on parse err
+func IsZeroTime(t time.Time) bool {
+	return t.IsZero()
+}
+
 // ParseUTCTime parse yyyy-mm-dd HH:MM:SS as utc time
 // when parse err, return a empty time.Time
 func ParseUTCTime(timeStr string) time.Time {
@@ -56,3 +178,236 @@ func ParseUTCTime(timeStr string) time.Time {
 		return time.Time{}
 	}
 }
+
+// MustParseUTCTime parse yyyy-mm-dd HH:MM:SS as utc time like ParseUTCTime,
+// but panics when parse err, for package level vars and test fixtures
+func MustParseUTCTime(timeStr string) time.Time {
+	result, err := time.Parse(ISOTimeFormat, timeStr)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// ParseClockString parse HH:MM:SS or HH:MM as duration since midnight, every part is 1 or 2 digits,
+// it is the inverse of the clock string SetField produced from microseconds
+func ParseClockString(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, fmt.Errorf("invalid clock string %q, want HH:MM:SS or HH:MM", s)
+	}
+
+	var nums [3]int
+	for i, part := range parts {
+		if !isClockPart(part) {
+			return 0, fmt.Errorf("invalid clock string %q, want HH:MM:SS or HH:MM", s)
+		}
+		nums[i], _ = strconv.Atoi(part)
+	}
+
+	hours, minutes, seconds := nums[0], nums[1], nums[2]
+	if hours >= 24 {
+		return 0, fmt.Errorf("invalid clock string %q, clock must be less than 24h", s)
+	}
+	if minutes >= 60 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid clock string %q, minutes and seconds must be less than 60", s)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second, nil
+}
+
+// isClockPart reports whether part is 1 or 2 plain digits, strconv.Atoi alone also takes signs
+func isClockPart(part string) bool {
+	if len(part) == 0 || len(part) > 2 {
+		return false
+	}
+	for i := 0; i < len(part); i++ {
+		if part[i] < '0' || part[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseUTCTimeWithLayouts parse s with the layouts in order and return the first success in utc,
+// times without zone are taken as utc. no layouts means ISOTimeFormat, the error lists the tried layouts
+func ParseUTCTimeWithLayouts(s string, layouts ...string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = []string{ISOTimeFormat}
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q with any of the layouts %q", s, layouts)
+}
+
+// CombineDateAndClock return the time at clock(HH:MM:SS or HH:MM, see ParseClockString) on the day of date
+// in date's location, for dates and clocks stored apart. clocks of 24h or more would roll over
+// to the next day and are an error
+func CombineDateAndClock(date time.Time, clock string) (time.Time, error) {
+	d, err := ParseClockString(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+	year, month, day := date.Date()
+	return time.Date(year, month, day, hours, minutes, seconds, 0, date.Location()), nil
+}
+
+// ParseISOFlexible parse yyyy-mm-dd HH:MM:SS with an optional trailing "Z" or
+// numeric offset like "+07:00"/"+0700", without zone the time is taken as utc.
+// unlike ParseUTCTime the parse error is returned, ErrEmptyTime for an empty s,
+// so a returned zero time with nil error is a real zero timestamp
+func ParseISOFlexible(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, ErrEmptyTime
+	}
+	for _, layout := range []string{ISOTimeFormat, ISOTimeFormat + "Z07:00", ISOTimeFormat + "Z0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as %s with optional zone", s, ISOTimeFormat)
+}
+
+// ParseDateOnly parse yyyy-mm-dd as utc midnight of the date,
+// unlike ParseUTCTime the parse error is returned, ErrEmptyTime for an empty s
+func ParseDateOnly(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, ErrEmptyTime
+	}
+	t, err := time.Parse(DateOnlyFormat, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q as %s: %w", s, DateOnlyFormat, err)
+	}
+	return t, nil
+}
+
+// FormatDateOnly format the date of t in its own location as yyyy-mm-dd
+func FormatDateOnly(t time.Time) string {
+	return t.Format(DateOnlyFormat)
+}
+
+var (
+	timeNamesMu  sync.RWMutex
+	weekdayNames *[7]string
+	monthNames   *[12]string
+)
+
+// SetWeekdayNames set the names WeekdayName returns, indexed by time.Weekday(Sunday is 0)
+func SetWeekdayNames(names [7]string) {
+	timeNamesMu.Lock()
+	defer timeNamesMu.Unlock()
+	weekdayNames = &names
+}
+
+// SetMonthNames set the names MonthName returns, names[0] is January
+func SetMonthNames(names [12]string) {
+	timeNamesMu.Lock()
+	defer timeNamesMu.Unlock()
+	monthNames = &names
+}
+
+// ResetTimeNames restore the english weekday and month names
+func ResetTimeNames() {
+	timeNamesMu.Lock()
+	defer timeNamesMu.Unlock()
+	weekdayNames = nil
+	monthNames = nil
+}
+
+// WeekdayName return the weekday name of t, english unless SetWeekdayNames is called
+func WeekdayName(t time.Time) string {
+	timeNamesMu.RLock()
+	defer timeNamesMu.RUnlock()
+	if weekdayNames == nil {
+		return t.Weekday().String()
+	}
+	return weekdayNames[t.Weekday()]
+}
+
+// MonthName return the month name of t, english unless SetMonthNames is called
+func MonthName(t time.Time) string {
+	timeNamesMu.RLock()
+	defer timeNamesMu.RUnlock()
+	if monthNames == nil {
+		return t.Month().String()
+	}
+	return monthNames[t.Month()-1]
+}
+
+// named time styles of FormatFriendly
+const (
+	TimeStyleDate     = "date"
+	TimeStyleDateTime = "datetime"
+	TimeStyleTime     = "time"
+	TimeStyleLong     = "long"
+)
+
+var (
+	timeStylesMu sync.RWMutex
+	timeStyles   = map[string]string{
+		TimeStyleDate:     DateOnlyFormat,
+		TimeStyleDateTime: ISOTimeFormat,
+		TimeStyleTime:     "15:04:05",
+		TimeStyleLong:     "Monday, January 2, 2006 15:04:05 MST",
+	}
+)
+
+// RegisterTimeStyle add or replace the layout of a FormatFriendly style
+func RegisterTimeStyle(style string, layout string) {
+	timeStylesMu.Lock()
+	defer timeStylesMu.Unlock()
+	timeStyles[style] = layout
+}
+
+// TimeStyleLayout return the layout of style and whether the style is known
+func TimeStyleLayout(style string) (string, bool) {
+	timeStylesMu.RLock()
+	defer timeStylesMu.RUnlock()
+	layout, ok := timeStyles[style]
+	return layout, ok
+}
+
+// FormatFriendly format t with the layout of a named style("date", "datetime", "time", "long"
+// or one added by RegisterTimeStyle), an unknown style formats with FormatISO.
+// the "Monday" and "January" layout tokens use WeekdayName and MonthName, so they follow
+// SetWeekdayNames and SetMonthNames
+func FormatFriendly(t time.Time, style string) string {
+	layout, ok := TimeStyleLayout(style)
+	if !ok {
+		return FormatISO(t)
+	}
+	return formatWithNames(t, layout)
+}
+
+// formatWithNames format t with layout, the "Monday" and "January" tokens are replaced by
+// WeekdayName and MonthName, the other parts of layout are formatted by time.Format
+func formatWithNames(t time.Time, layout string) string {
+	var sb strings.Builder
+	for {
+		weekdayAt := strings.Index(layout, "Monday")
+		monthAt := strings.Index(layout, "January")
+		if weekdayAt < 0 && monthAt < 0 {
+			sb.WriteString(t.Format(layout))
+			return sb.String()
+		}
+
+		if monthAt < 0 || (weekdayAt >= 0 && weekdayAt < monthAt) {
+			sb.WriteString(t.Format(layout[:weekdayAt]))
+			sb.WriteString(WeekdayName(t))
+			layout = layout[weekdayAt+len("Monday"):]
+		} else {
+			sb.WriteString(t.Format(layout[:monthAt]))
+			sb.WriteString(MonthName(t))
+			layout = layout[monthAt+len("January"):]
+		}
+	}
+}
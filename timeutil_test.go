@@ -0,0 +1,25 @@
+package ygrpcgoutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClockString(t *testing.T) {
+	valid := map[string]time.Duration{
+		"08:30":    8*time.Hour + 30*time.Minute,
+		"8:5:9":    8*time.Hour + 5*time.Minute + 9*time.Second,
+		"23:59:59": 23*time.Hour + 59*time.Minute + 59*time.Second,
+	}
+	for s, want := range valid {
+		if got, err := ParseClockString(s); err != nil || got != want {
+			t.Errorf("ParseClockString(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"+1:-0", "+1:00", "01:-0", "001:00", "1:", "24:00", "12:60", " 1:00", "1:00:00:00"} {
+		if got, err := ParseClockString(s); err == nil {
+			t.Errorf("ParseClockString(%q) = %v, want error", s, got)
+		}
+	}
+}
@@ -0,0 +1,774 @@
+package ygrpcgoutil
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/google/uuid"
+)
+
+const (
+	microsecondsPerSecond = 1000000
+	microsecondsPerMinute = 60 * microsecondsPerSecond
+	microsecondsPerHour   = 60 * microsecondsPerMinute
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+var (
+	netIPType     = reflect.TypeOf(net.IP{})
+	netipAddrType = reflect.TypeOf(netip.Addr{})
+	urlType       = reflect.TypeOf(url.URL{})
+)
+
+var mapStringInterfaceType = reflect.TypeOf(map[string]interface{}{})
+
+var binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+
+// maxPointerDepth is how many pointers of a source value like **int32 SetField dereferences
+const maxPointerDepth = 8
+
+// TagKeyEpoch is the field tag selecting the unit a time.Time is set into an int64/int field with,
+// `epoch:"s"`, `epoch:"ms"`(default), `epoch:"us"` or `epoch:"ns"`
+const TagKeyEpoch = "epoch"
+
+// TagKeyTZ is the field tag naming the IANA zone a string is parsed in when set into a time.Time field,
+// like `tz:"America/New_York"`, strings without zone are utc by default
+const TagKeyTZ = "tz"
+
+var errTypeMismatch = errors.New("value type didn't match obj field type")
+
+// ConvertValue converts value to the target type with the same rules SetField uses
+// (time, uuid, numbers, json, pointers...) and returns the converted value without setting anything.
+// a nil value converts to the zero value of target
+func ConvertValue(value interface{}, target reflect.Type) (interface{}, error) {
+	converted, err := defaultMapper.convertValue(reflect.ValueOf(value), reflect.StructField{Type: target}, target)
+	if err != nil {
+		return nil, err
+	}
+	if !converted.IsValid() {
+		return reflect.Zero(target).Interface(), nil
+	}
+	return converted.Interface(), nil
+}
+
+// convertValue converts val to structFieldType with the SetField conversion rules,
+// field is the struct field being set, its name and tags select name/tag dependent conversions.
+// the returned value is invalid when the field should be left unchanged (nil val for non pointer types),
+// nil val for pointer types returns the nil pointer
+func (m *Mapper) convertValue(val reflect.Value, field reflect.StructField, structFieldType reflect.Type) (reflect.Value, error) {
+	name := field.Name
+
+	if val.Kind() == reflect.Interface {
+		//elements of []interface{} and map[string]interface{} values, like decoded json
+		if val.IsNil() {
+			val = reflect.Value{}
+		} else {
+			val = val.Elem()
+		}
+	}
+
+	if !val.IsValid() {
+		if structFieldType.Kind() == reflect.Ptr {
+			return reflect.Zero(structFieldType), nil
+		}
+		return reflect.Value{}, nil
+	}
+
+	for depth := 0; val.Kind() == reflect.Ptr && structFieldType != val.Type() && !implementsInterface(val.Type(), structFieldType); depth++ {
+		//dereference pointer chains like **int32, nil pointer clears pointer fields and is ignored otherwise,
+		//pointers implementing an interface field are set as they are
+		if depth == maxPointerDepth {
+			return reflect.Value{}, fmt.Errorf("pointer chain of %s is deeper than %d", val.Type().String(), maxPointerDepth)
+		}
+		if val.IsNil() {
+			return m.convertValue(reflect.Value{}, field, structFieldType)
+		}
+		val = val.Elem()
+	}
+	value := val.Interface()
+
+	if valuer, ok := value.(driver.Valuer); ok && val.Kind() == reflect.Struct && !val.Type().AssignableTo(structFieldType) {
+		//database values like sql.NullString, NULL clears pointer fields and is ignored otherwise
+		dbValue, err := valuer.Value()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if dbValue == nil {
+			return m.convertValue(reflect.Value{}, field, structFieldType)
+		}
+		value = dbValue
+		val = reflect.ValueOf(value)
+	}
+
+	if val.Type() == structFieldType && (structFieldType == bigIntType || structFieldType == bigFloatType) {
+		//a big number copied as a struct shares its digits with the source, math/big needs Set
+		converted, err := convertBigNumber(val, structFieldType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return converted, nil
+	}
+
+	if structFieldType != val.Type() {
+		//fmt.Println("name:", name, "v type:", val.Type().String())
+		if converter, ok := m.converters[converterKey{from: val.Type(), to: structFieldType}]; ok {
+			converted, err := converter(value)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val = reflect.ValueOf(converted)
+			if !val.IsValid() || !val.Type().AssignableTo(structFieldType) {
+				return reflect.Value{}, fmt.Errorf("converter returned %T, want %s", converted, structFieldType.String())
+			}
+			goto SETVALUE
+		}
+
+		if converted, ok, err := convertNetValue(val, structFieldType); ok {
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val = converted
+			goto SETVALUE
+		}
+
+		if implementsInterface(val.Type(), structFieldType) {
+			//interface fields like io.Reader accept any implementation
+			goto SETVALUE
+		}
+
+		if structFieldType.Kind() == reflect.Ptr {
+			//optional field like *int32, convert val into a new element
+			elem, err := m.convertValue(val, field, structFieldType.Elem())
+			if err != nil || !elem.IsValid() {
+				return elem, err
+			}
+			ptr := reflect.New(structFieldType.Elem())
+			ptr.Elem().Set(elem)
+			return ptr, nil
+		}
+
+		if isArrayToSlice(val.Type(), structFieldType) || isArrayToSlice(structFieldType, val.Type()) {
+			//copy between fixed array and slice of same length
+			var converted reflect.Value
+			if structFieldType.Kind() == reflect.Array {
+				if val.Len() != structFieldType.Len() {
+					return reflect.Value{}, fmt.Errorf("cannot set %d elements into %s", val.Len(), structFieldType.String())
+				}
+				converted = reflect.New(structFieldType).Elem()
+			} else {
+				converted = reflect.MakeSlice(structFieldType, val.Len(), val.Len())
+			}
+			reflect.Copy(converted, val)
+			val = converted
+			goto SETVALUE
+		}
+
+		if val.Kind() == reflect.String && isBytesType(structFieldType) {
+			val = val.Convert(structFieldType)
+			goto SETVALUE
+		}
+
+		if val.Kind() == reflect.String && structFieldType.Kind() == reflect.Array && structFieldType.Elem().Kind() == reflect.Uint8 {
+			//fixed ids like [16]byte, text types like uuid.UUID parse the string,
+			//others get the string bytes, truncated to the array length or zero padded
+			ptr := reflect.New(structFieldType)
+			if unmarshaler, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+				if err := unmarshaler.UnmarshalText([]byte(val.String())); err != nil {
+					return reflect.Value{}, err
+				}
+			} else {
+				reflect.Copy(ptr.Elem(), reflect.ValueOf([]byte(val.String())))
+			}
+			val = ptr.Elem()
+			goto SETVALUE
+		}
+
+		if isBytesType(val.Type()) && reflect.PointerTo(structFieldType).Implements(binaryUnmarshalerType) {
+			ptr := reflect.New(structFieldType)
+			if err := ptr.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(val.Bytes()); err != nil {
+				return reflect.Value{}, err
+			}
+			val = ptr.Elem()
+			goto SETVALUE
+		}
+
+		if isBytesType(structFieldType) {
+			if marshaler, ok := value.(encoding.BinaryMarshaler); ok {
+				b, err := marshaler.MarshalBinary()
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				val = reflect.ValueOf(b)
+				goto SETVALUE
+			}
+		}
+
+		if structFieldType.Kind() == reflect.Struct && val.Type() == mapStringInterfaceType {
+			//decoded json object into a nested struct
+			ptr := reflect.New(structFieldType)
+			if err := m.mapToStruct(ptr.Interface(), value.(map[string]interface{})); err != nil {
+				return reflect.Value{}, err
+			}
+			val = ptr.Elem()
+			goto SETVALUE
+		}
+
+		if isStructSliceType(structFieldType) && val.Kind() == reflect.Slice &&
+			(val.Type().Elem() == mapStringInterfaceType || val.Type().Elem().Kind() == reflect.Interface) {
+			//decoded json array of objects
+			converted := reflect.MakeSlice(structFieldType, val.Len(), val.Len())
+			for i := 0; i < val.Len(); i++ {
+				elemMap, ok := val.Index(i).Interface().(map[string]interface{})
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("index %d: element is %T, want map[string]interface{}", i, val.Index(i).Interface())
+				}
+				elem := converted.Index(i)
+				if elem.Kind() == reflect.Ptr {
+					elem.Set(reflect.New(elem.Type().Elem()))
+				} else {
+					elem = elem.Addr()
+				}
+				if err := m.mapToStruct(elem.Interface(), elemMap); err != nil {
+					return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			val = converted
+			goto SETVALUE
+		}
+
+		if structFieldType.Kind() == reflect.Slice && val.Kind() == reflect.Slice && !val.Type().ConvertibleTo(structFieldType) {
+			//slices of different element types like []int64 => []int32, convert element by element
+			if val.IsNil() {
+				val = reflect.Zero(structFieldType)
+				goto SETVALUE
+			}
+			converted := reflect.MakeSlice(structFieldType, val.Len(), val.Len())
+			for i := 0; i < val.Len(); i++ {
+				elem, err := m.convertValue(val.Index(i), field, structFieldType.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
+				}
+				if elem.IsValid() {
+					converted.Index(i).Set(elem)
+				}
+			}
+			val = converted
+			goto SETVALUE
+		}
+
+		if structFieldType == bigIntType || structFieldType == bigFloatType {
+			converted, err := convertBigNumber(val, structFieldType)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val = converted
+			goto SETVALUE
+		}
+
+		if structFieldType == durationType && val.Kind() == reflect.String {
+			d, err := time.ParseDuration(val.String())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val = reflect.ValueOf(d)
+			goto SETVALUE
+		}
+
+		if structFieldType == timeType && val.Kind() == reflect.String {
+			t, err := parseTimeInZone(val.String(), field.Tag.Get(TagKeyTZ))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val = reflect.ValueOf(t)
+			goto SETVALUE
+		}
+
+		if val.Kind() == reflect.String && structFieldType.Kind() == reflect.Int32 {
+			//registered enum name like "ACTIVE"
+			if v, ok, err := enumValue(structFieldType, val.String()); ok {
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				val = reflect.ValueOf(v).Convert(structFieldType)
+				goto SETVALUE
+			}
+		}
+
+		if number, ok := value.(json.Number); ok && isNumberKind(structFieldType.Kind()) {
+			//json decoded with UseNumber, parse it and convert as a number below
+			if i, err := number.Int64(); err == nil {
+				val = reflect.ValueOf(i)
+			} else if u, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+				val = reflect.ValueOf(u)
+			} else if f, err := number.Float64(); err == nil {
+				val = reflect.ValueOf(f)
+			} else {
+				return reflect.Value{}, err
+			}
+			value = val.Interface()
+		}
+
+		switch structFieldType.Kind() {
+
+		case reflect.String:
+			if val.Kind() == reflect.Int32 {
+				if enumStr, ok := enumName(val.Type(), int32(val.Int())); ok {
+					val = reflect.ValueOf(enumStr)
+					goto SETVALUE
+				}
+			}
+			switch val.Type().String() {
+			case "big.Int", "big.Float":
+				//String has a pointer receiver
+				ptr := reflect.New(val.Type())
+				ptr.Elem().Set(val)
+				if b, ok := ptr.Interface().(*big.Int); ok {
+					val = reflect.ValueOf(b.String())
+				} else {
+					val = reflect.ValueOf(ptr.Interface().(*big.Float).Text('f', -1))
+				}
+				goto SETVALUE
+			case "time.Time":
+				valTime := value.(time.Time)
+				val = reflect.ValueOf(FormatISO(valTime))
+				goto SETVALUE
+			case "[]uint8":
+				valUuid := value.([]uint8)
+				val = reflect.ValueOf(string(valUuid))
+				goto SETVALUE
+
+			case "[16]uint8":
+				uuid16 := value.([16]uint8)
+				uuidv := *(*uuid.UUID)(unsafe.Pointer(&uuid16))
+				val = reflect.ValueOf(uuidv.String())
+				goto SETVALUE
+
+			case "map[string]interface {}":
+				//json
+				b, err := json.Marshal(value)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				val = reflect.ValueOf(string(b))
+				goto SETVALUE
+
+			case "int32":
+				if WarnInt2StrInSetField {
+					m.logf("setfield to string warn: %s %s", name, val.Type().String())
+				}
+				v32 := value.(int32)
+				val = reflect.ValueOf(strconv.Itoa(int(v32)))
+				goto SETVALUE
+			case "bool":
+				val = reflect.ValueOf(strconv.FormatBool(value.(bool)))
+				goto SETVALUE
+			case "int64":
+				usec := value.(int64)
+
+				if strings.Contains(name, "Time") || strings.Contains(name, "time") {
+					//time format, Number of microseconds since midnight
+					hours := usec / microsecondsPerHour
+					usec -= hours * microsecondsPerHour
+					minutes := usec / microsecondsPerMinute
+					usec -= minutes * microsecondsPerMinute
+					seconds := usec / microsecondsPerSecond
+					//usec -= seconds * microsecondsPerSecond
+
+					s := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+					val = reflect.ValueOf(s)
+				} else {
+					s := strconv.FormatInt(usec, 10)
+					val = reflect.ValueOf(s)
+				}
+				goto SETVALUE
+
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if val.Type() == timeType && (structFieldType.Kind() == reflect.Int || structFieldType.Kind() == reflect.Int64) {
+				//unix epoch, unit selected by the epoch tag
+				epoch, err := epochOf(value.(time.Time), field.Tag.Get(TagKeyEpoch))
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				val = reflect.ValueOf(epoch).Convert(structFieldType)
+				goto SETVALUE
+			}
+			if val.Kind() == reflect.String {
+				//numeric text like "42" is only parsed as a number for SetFieldsFromEnv(parseStrings), integer
+				//fields reject fractions. a single non digit character into rune(int32) or byte(uint8) fields
+				//is its code point, "a" is 97, bytes only take code points up to 255. a single digit is a
+				//number or a type mismatch like other numeric text, never its code point
+				kind := structFieldType.Kind()
+				parsed, err := reflect.Value{}, fmt.Errorf("%w %s:%s", errTypeMismatch, structFieldType.String(), val.Type().String())
+				if m.parseStrings {
+					parsed, err = parseNumberString(val.String(), structFieldType)
+				}
+				if r, ok := singleRune(val.String()); err != nil && ok && (r < '0' || r > '9') && (kind == reflect.Int32 || kind == reflect.Uint8) {
+					if kind == reflect.Uint8 && r > math.MaxUint8 {
+						return reflect.Value{}, fmt.Errorf("character %q overflows %s", r, structFieldType.String())
+					}
+					parsed, err = reflect.ValueOf(int64(r)), nil
+				}
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				val = parsed
+			}
+			if val.Kind() == reflect.Bool {
+				//true => 1, false => 0
+				if val.Bool() {
+					val = reflect.ValueOf(1)
+				} else {
+					val = reflect.ValueOf(0)
+				}
+			}
+			if isNumberKind(val.Kind()) {
+				converted, err := convertNumber(val, structFieldType, m.strict)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				val = converted
+				goto SETVALUE
+			}
+		case reflect.Bool:
+			if isNumberKind(val.Kind()) {
+				//non zero number => true
+				val = reflect.ValueOf(!val.IsZero()).Convert(structFieldType)
+				goto SETVALUE
+			}
+			if val.Kind() == reflect.String && m.parseStrings {
+				//"true", "1", "f"... like strconv.ParseBool
+				b, err := strconv.ParseBool(strings.TrimSpace(val.String()))
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("cannot parse %q as bool", val.String())
+				}
+				val = reflect.ValueOf(b).Convert(structFieldType)
+				goto SETVALUE
+			}
+		}
+		if val.Kind() == structFieldType.Kind() && val.Type().ConvertibleTo(structFieldType) {
+			//named types like `type Status string` accept their underlying type
+			goto SETVALUE
+		}
+		return reflect.Value{}, fmt.Errorf("%w %s:%s", errTypeMismatch, structFieldType.String(), val.Type().String())
+	}
+SETVALUE:
+	if val.Type() != structFieldType && val.Type().ConvertibleTo(structFieldType) {
+		val = val.Convert(structFieldType)
+	}
+	return val, nil
+}
+
+// isBytesType reports whether t is []byte or a named type of it
+func isBytesType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// implementsInterface reports whether typ is an interface type implemented by t
+func implementsInterface(t, typ reflect.Type) bool {
+	return typ.Kind() == reflect.Interface && t.Implements(typ)
+}
+
+// parseTimeInZone parse s with the ISOTimeLayout, or as RFC3339 when it carries a zone,
+// a time without zone is in the IANA zone(utc for ""). an empty s is the zero time
+func parseTimeInZone(s string, zone string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	loc := time.UTC
+	if zone != "" {
+		var err error
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s tag: %w", TagKeyTZ, err)
+		}
+	}
+
+	layout := ISOTimeLayout()
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err == nil {
+		return t, nil
+	}
+	if t, errRFC := time.Parse(time.RFC3339Nano, s); errRFC == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as %s: %w", s, layout, err)
+}
+
+// epochOf returns the unix epoch of t in unit "s", "ms"(the default for ""), "us" or "ns",
+// the zero time is 0
+func epochOf(t time.Time, unit string) (int64, error) {
+	if t.IsZero() {
+		return 0, nil
+	}
+
+	switch unit {
+	case "s":
+		return t.Unix(), nil
+	case "", "ms":
+		return t.UnixMilli(), nil
+	case "us":
+		return t.UnixMicro(), nil
+	case "ns":
+		return t.UnixNano(), nil
+	default:
+		return 0, fmt.Errorf("unknown epoch unit %q, want s, ms, us or ns", unit)
+	}
+}
+
+// convertNetValue parses a string val into net.IP, netip.Addr and url.URL typ and formats them
+// back into string typ, ok is false when val and typ are not such a pair
+func convertNetValue(val reflect.Value, typ reflect.Type) (converted reflect.Value, ok bool, err error) {
+	if val.Kind() == reflect.String {
+		s := val.String()
+		switch typ {
+		case netIPType:
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return reflect.Value{}, true, fmt.Errorf("invalid ip address %q", s)
+			}
+			return reflect.ValueOf(ip), true, nil
+		case netipAddrType:
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			return reflect.ValueOf(addr), true, nil
+		case urlType:
+			u, err := url.Parse(s)
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			return reflect.ValueOf(*u), true, nil
+		}
+	}
+
+	if typ.Kind() == reflect.String {
+		var s string
+		switch val.Type() {
+		case netIPType:
+			s = val.Interface().(net.IP).String()
+		case netipAddrType:
+			s = val.Interface().(netip.Addr).String()
+		case urlType:
+			u := val.Interface().(url.URL)
+			s = u.String()
+		default:
+			return reflect.Value{}, false, nil
+		}
+		return reflect.ValueOf(s).Convert(typ), true, nil
+	}
+
+	return reflect.Value{}, false, nil
+}
+
+// isStructSliceType reports whether t is []struct or []*struct
+func isStructSliceType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	elemType := t.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	return elemType.Kind() == reflect.Struct
+}
+
+// convertBigNumber converts a number, numeric string, big.Int or big.Float val to big.Int or big.Float typ,
+// float to big.Int truncates
+func convertBigNumber(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	bf := new(big.Float)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bf.SetInt64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bf.SetUint64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return reflect.Value{}, fmt.Errorf("cannot convert %v to %s", f, typ.String())
+		}
+		bf.SetFloat64(f)
+	case reflect.String:
+		if typ == bigIntType {
+			bi, ok := new(big.Int).SetString(val.String(), 10)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("invalid integer %q", val.String())
+			}
+			return reflect.ValueOf(bi).Elem(), nil
+		}
+		if _, ok := bf.SetString(val.String()); !ok {
+			return reflect.Value{}, fmt.Errorf("invalid number %q", val.String())
+		}
+	case reflect.Struct:
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		switch v := ptr.Interface().(type) {
+		case *big.Int:
+			if typ == bigIntType {
+				return reflect.ValueOf(new(big.Int).Set(v)).Elem(), nil
+			}
+			bf.SetInt(v)
+		case *big.Float:
+			bf.Set(v)
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot convert %s to %s", val.Type().String(), typ.String())
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot convert %s to %s", val.Type().String(), typ.String())
+	}
+
+	if typ == bigIntType {
+		bi, _ := bf.Int(nil)
+		return reflect.ValueOf(bi).Elem(), nil
+	}
+	return reflect.ValueOf(bf).Elem(), nil
+}
+
+// isArrayToSlice reports whether from is an array and to is a slice of the same element type
+func isArrayToSlice(from, to reflect.Type) bool {
+	return from.Kind() == reflect.Array && to.Kind() == reflect.Slice && from.Elem() == to.Elem()
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseNumberString parses s(surrounding spaces ignored) as a number for the number type typ,
+// int64 or uint64 for integer types(fractions are an error), float64 for float types
+func parseNumberString(s string, typ reflect.Type) (reflect.Value, error) {
+	text := strings.TrimSpace(s)
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return reflect.ValueOf(f), nil
+		}
+	default:
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return reflect.ValueOf(i), nil
+		}
+		if u, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return reflect.ValueOf(u), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("cannot parse %q as %s", s, typ.String())
+}
+
+// singleRune returns the only character of s, ok is false when s is not exactly one valid character
+func singleRune(s string) (rune, bool) {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || size != len(s) {
+		return 0, false
+	}
+	return r, true
+}
+
+// convertNumber converts a int/uint/float val to the number type typ,
+// values that typ can not hold are wrapped silently unless strict is set,
+// negative values into unsigned types are always an error
+func convertNumber(val reflect.Value, typ reflect.Type, strict bool) (reflect.Value, error) {
+	result := reflect.New(typ).Elem()
+	overflowErr := func() error {
+		return fmt.Errorf("value %v overflows %s", val.Interface(), typ.String())
+	}
+	negativeErr := func() error {
+		return fmt.Errorf("negative value %v can not be set into unsigned %s", val.Interface(), typ.String())
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch val.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u := val.Uint()
+			if strict && u > math.MaxInt64 {
+				return result, overflowErr()
+			}
+			i = int64(u)
+		case reflect.Float32, reflect.Float64:
+			f := val.Float()
+			if strict && (f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64) {
+				return result, fmt.Errorf("value %v can not be represented by %s", f, typ.String())
+			}
+			i = int64(f)
+		default:
+			i = val.Int()
+		}
+		if strict && result.OverflowInt(i) {
+			return result, overflowErr()
+		}
+		result.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch val.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = val.Uint()
+		case reflect.Float32, reflect.Float64:
+			f := val.Float()
+			if f < 0 {
+				return result, negativeErr()
+			}
+			if strict && (f != math.Trunc(f) || f >= math.MaxUint64) {
+				return result, fmt.Errorf("value %v can not be represented by %s", f, typ.String())
+			}
+			u = uint64(f)
+		default:
+			i := val.Int()
+			if i < 0 {
+				//never wrap negatives around to huge unsigned values
+				return result, negativeErr()
+			}
+			u = uint64(i)
+		}
+		if strict && result.OverflowUint(u) {
+			return result, overflowErr()
+		}
+		result.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(val.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(val.Uint())
+		default:
+			f = val.Float()
+		}
+		if strict && result.OverflowFloat(f) {
+			return result, overflowErr()
+		}
+		result.SetFloat(f)
+	default:
+		return result, fmt.Errorf("%s is not a number type", typ.String())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package ygrpcgoutil
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestSetFieldSingleCharacter(t *testing.T) {
+	type chars struct {
+		R rune
+		B byte
+		I int
+	}
+
+	var c chars
+	if err := SetField(&c, "R", "a"); err != nil || c.R != 'a' {
+		t.Errorf("SetField rune \"a\" = %d, %v, want %d", c.R, err, 'a')
+	}
+	if err := SetField(&c, "B", "a"); err != nil || c.B != 'a' {
+		t.Errorf("SetField byte \"a\" = %d, %v, want %d", c.B, err, 'a')
+	}
+	if err := SetField(&c, "B", "€"); err == nil {
+		t.Errorf("SetField byte \"€\" = %d, want overflow error", c.B)
+	}
+	if err := SetField(&c, "R", "7"); err == nil {
+		t.Errorf("SetField rune \"7\" = %d, want type mismatch error", c.R)
+	}
+	if err := SetField(&c, "I", "7"); err == nil {
+		t.Errorf("SetField int \"7\" = %d, want type mismatch error", c.I)
+	}
+}
+
+func TestSetFieldsFromEnvSingleDigit(t *testing.T) {
+	t.Setenv("YG_TEST_WORKERS", "4")
+	t.Setenv("YG_TEST_LEVEL", "3")
+	t.Setenv("YG_TEST_SEP", ",")
+	t.Setenv("YG_TEST_PORT", "42")
+	var e struct {
+		Workers int32 `env:"YG_TEST_WORKERS"`
+		Level   uint8 `env:"YG_TEST_LEVEL"`
+		Sep     rune  `env:"YG_TEST_SEP"`
+		Port    int   `env:"YG_TEST_PORT"`
+	}
+	if err := SetFieldsFromEnv(&e, ""); err != nil {
+		t.Fatalf("SetFieldsFromEnv: %v", err)
+	}
+	if e.Workers != 4 || e.Level != 3 || e.Sep != ',' || e.Port != 42 {
+		t.Errorf("SetFieldsFromEnv = %+v, want {Workers:4 Level:3 Sep:44 Port:42}", e)
+	}
+}
+
+func TestSetFieldInterfaceElements(t *testing.T) {
+	var s struct {
+		Tags   []string
+		Scores []int
+	}
+	if err := SetField(&s, "Tags", []interface{}{"a", "b"}); err != nil {
+		t.Fatalf("SetField []interface{} into []string: %v", err)
+	}
+	if len(s.Tags) != 2 || s.Tags[0] != "a" || s.Tags[1] != "b" {
+		t.Errorf("Tags = %v, want [a b]", s.Tags)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"Tags":["x","y"],"Scores":[1,2]}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := MapToStruct(&s, decoded); err != nil {
+		t.Fatalf("MapToStruct: %v", err)
+	}
+	if len(s.Tags) != 2 || s.Tags[0] != "x" || len(s.Scores) != 2 || s.Scores[1] != 2 {
+		t.Errorf("MapToStruct = %+v", s)
+	}
+}
+
+func TestSetFieldBigNumberCopy(t *testing.T) {
+	var s struct {
+		I big.Int
+		F big.Float
+	}
+
+	srcInt := big.NewInt(1 << 40)
+	if err := SetField(&s, "I", srcInt); err != nil {
+		t.Fatal(err)
+	}
+	srcInt.SetInt64(7)
+	if s.I.Int64() != 1<<40 {
+		t.Errorf("I = %v after changing the source, want %d", &s.I, int64(1<<40))
+	}
+
+	srcFloat := big.NewFloat(1.5)
+	if err := SetField(&s, "F", *srcFloat); err != nil {
+		t.Fatal(err)
+	}
+	srcFloat.SetFloat64(7)
+	if f, _ := s.F.Float64(); f != 1.5 {
+		t.Errorf("F = %v after changing the source, want 1.5", &s.F)
+	}
+}
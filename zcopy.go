@@ -0,0 +1,415 @@
+package ygrpcgoutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Convert 将src转换到dst, 用于分层struct之间的映射(request => model => response)
+// dst必须是struct指针, src可以是struct或struct指针, 字段按名字匹配(包含匿名嵌入struct的字段),
+// 类型不同时使用SetField的所有类型转换规则, dst中没有的字段忽略.
+// 与CopyFields不同, 类型不同的嵌套struct(或struct指针)字段和struct slice字段会按字段名递归转换,
+// 字段按FieldsDeepOrdered的顺序设置, 出错时继续转换其余字段, 返回包含所有错误的*MultiError.
+// 需要更细的控制时使用CopyFields/SetField
+func Convert(dst, src interface{}) error {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return errors.New("dst must be a pointer to struct")
+	}
+
+	names, err := FieldsDeepOrdered(src)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	for _, name := range names {
+		if ok, _ := HasField(dst, name); !ok {
+			continue
+		}
+		value, errGet := GetField(src, name)
+		if errGet != nil {
+			//promoted through a nil embedded pointer
+			continue
+		}
+		errs.add(name, defaultMapper.convertField(dst, name, value))
+	}
+
+	return errs.errOrNil()
+}
+
+// ConvertByTag 与Convert相同, 但字段按tagKey的tag名匹配, 两边都必须有相同的tag名
+func ConvertByTag(dst, src interface{}, tagKey string) error {
+	pairs, err := MatchFieldsByTags(dst, src, tagKey, tagKey)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	for _, dstName := range sortedKeys(pairs) {
+		value, errGet := GetField(src, pairs[dstName])
+		if errGet != nil {
+			continue
+		}
+		errs.add(dstName, defaultMapper.convertField(dst, dstName, value))
+	}
+
+	return errs.errOrNil()
+}
+
+// convertField sets value into the name field of dst, nested structs and struct slices of
+// other types are converted with Convert/ConvertSlice, other values with SetField
+func (m *Mapper) convertField(dst interface{}, name string, value interface{}) error {
+	fieldValue, err := FieldValue(dst, name)
+	if err != nil || !fieldValue.CanSet() || isSkipField(structFieldOf(dst, name)) {
+		return m.setField(dst, name, value)
+	}
+
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	fieldType := fieldValue.Type()
+
+	switch {
+	case val.Kind() == reflect.Struct && isNestedStructType(val.Type()) && isNestedStructType(derefPtrType(fieldType)) &&
+		val.Type() != derefPtrType(fieldType):
+		ptr := reflect.New(derefPtrType(fieldType))
+		if err := Convert(ptr.Interface(), val.Interface()); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if fieldType.Kind() == reflect.Ptr {
+			fieldValue.Set(ptr)
+		} else {
+			fieldValue.Set(ptr.Elem())
+		}
+		return nil
+	case val.Kind() == reflect.Slice && isStructSliceType(val.Type()) && isStructSliceType(fieldType) &&
+		!val.Type().AssignableTo(fieldType):
+		if val.IsNil() {
+			fieldValue.Set(reflect.Zero(fieldType))
+			return nil
+		}
+		ptr := reflect.New(fieldType)
+		if err := ConvertSlice(ptr.Interface(), val.Interface()); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fieldValue.Set(ptr.Elem())
+		return nil
+	}
+
+	return m.setField(dst, name, value)
+}
+
+func structFieldOf(obj interface{}, name string) reflect.StructField {
+	field, _ := ReflectValue(obj).Type().FieldByName(name)
+	return field
+}
+
+func derefPtrType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// sortedKeys returns the keys of m sorted, so functions walking a map fill and report fields in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isNestedStructType reports whether t is a plain struct Convert and WalkStruct descend, struct types
+// SetField converts as values(time.Time, big numbers, url.URL, netip.Addr) are not, neither are
+// structs without exportable fields, their state is unexported and only handled as a whole value
+func isNestedStructType(t reflect.Type) bool {
+	switch t {
+	case timeType, bigIntType, bigFloatType, urlType, netipAddrType:
+		return false
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if IsExportableField(t.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertViaJSON 将src用json编码后解码到dst, 字段按两边的json tag名匹配, 类型由json解码规则转换.
+// 用于字段类型与反射转换规则不匹配时的简单DTO转换, 比Convert慢, dst必须是非nil指针
+func ConvertViaJSON(dst, src interface{}) error {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return errors.New("dst must be a non nil pointer")
+	}
+
+	b, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("cannot marshal src: %w", err)
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("cannot unmarshal into dst: %w", err)
+	}
+
+	return nil
+}
+
+// ConvertSlice 将src slice([]Src或[]*Src)的每个元素用Convert转换到dstSlicePtr指向的slice中,
+// dstSlicePtr为*[]Dst或*[]*Dst, 目标slice重新分配, src中的nil元素转换为零值.
+// 元素转换出错时返回带下标的错误
+func ConvertSlice(dstSlicePtr interface{}, src interface{}) error {
+	dstValue := reflect.ValueOf(dstSlicePtr)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Slice {
+		return errors.New("dstSlicePtr must be a pointer to slice")
+	}
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Kind() != reflect.Slice && srcValue.Kind() != reflect.Array {
+		return errors.New("src must be a slice")
+	}
+
+	sliceType := dstValue.Elem().Type()
+	elemType := sliceType.Elem()
+	elemIsPtr := elemType.Kind() == reflect.Ptr
+	if elemIsPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("dstSlicePtr must be a pointer to slice of struct")
+	}
+
+	result := reflect.MakeSlice(sliceType, srcValue.Len(), srcValue.Len())
+	for i := 0; i < srcValue.Len(); i++ {
+		srcElem := srcValue.Index(i)
+		if (srcElem.Kind() == reflect.Ptr || srcElem.Kind() == reflect.Interface) && srcElem.IsNil() {
+			continue
+		}
+
+		dstElem := reflect.New(elemType)
+		if err := Convert(dstElem.Interface(), srcElem.Interface()); err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+		if elemIsPtr {
+			result.Index(i).Set(dstElem)
+		} else {
+			result.Index(i).Set(dstElem.Elem())
+		}
+	}
+
+	dstValue.Elem().Set(result)
+	return nil
+}
+
+// CopyFields 将src中的导出字段按字段名复制到dst中同名字段, dst必须是struct指针
+// 字段类型不同时使用SetField的类型转换, dst中没有的字段忽略
+// 出错时继续复制其余字段, 返回包含所有错误的*MultiError
+func CopyFields(dst, src interface{}) error {
+	return defaultMapper.copyFields(dst, src)
+}
+
+func (m *Mapper) copyFields(dst, src interface{}) (err error) {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return errors.New("dst must be a pointer to struct")
+	}
+
+	srcItems, err := ItemsDeep(src)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	for _, name := range sortedKeys(srcItems) {
+		if ok, _ := HasField(dst, name); !ok {
+			continue
+		}
+		errs.add(name, m.setField(dst, name, srcItems[name]))
+	}
+
+	return errs.errOrNil()
+}
+
+// ConvertWithMapping 与Convert相同, 但fieldMap将src字段名映射为dst字段名, 用于名字不同又没有共同tag的struct.
+// fieldMap中映射为""的src字段跳过, 未在fieldMap中的src字段仍按同名复制.
+// 映射到dst中不存在的字段是错误, 出错时继续复制其余字段, 返回包含所有错误的*MultiError
+func ConvertWithMapping(dst, src interface{}, fieldMap map[string]string) error {
+	return defaultMapper.convertWithMapping(dst, src, fieldMap)
+}
+
+func (m *Mapper) convertWithMapping(dst, src interface{}, fieldMap map[string]string) (err error) {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return errors.New("dst must be a pointer to struct")
+	}
+
+	srcItems, err := ItemsDeep(src)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	for _, name := range sortedKeys(srcItems) {
+		dstName, mapped := fieldMap[name]
+		if !mapped {
+			if ok, _ := HasField(dst, name); !ok {
+				continue
+			}
+			dstName = name
+		} else if dstName == "" {
+			continue
+		}
+
+		errs.add(dstName, m.convertField(dst, dstName, srcItems[name]))
+	}
+
+	return errs.errOrNil()
+}
+
+// CopyChangedFields 将src中与baseline不同的字段复制到dst中, 返回变化的字段名(DiffStructs的顺序),
+// 用于只保存用户修改过的字段. src与baseline必须是同一struct类型, dst中没有的字段忽略,
+// 出错时继续复制其余字段, 返回包含所有错误的*MultiError
+func CopyChangedFields(dst, src, baseline interface{}) (changed []string, err error) {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return nil, errors.New("dst must be a pointer to struct")
+	}
+
+	changed, err = DiffStructs(src, baseline)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs MultiError
+	for _, name := range changed {
+		if ok, _ := HasField(dst, name); !ok {
+			continue
+		}
+		value, errTmp := GetField(src, name)
+		if errTmp == nil {
+			errTmp = SetField(dst, name, value)
+		}
+		errs.add(name, errTmp)
+	}
+
+	return changed, errs.errOrNil()
+}
+
+// CopyFieldsByTag 将src中的字段按tagKey的tag名匹配复制到dst中, 两边都必须有相同的tag名
+func CopyFieldsByTag(dst, src interface{}, tagKey string) error {
+	_, err := CopyFieldsByTags(dst, src, tagKey, tagKey)
+	return err
+}
+
+// CopyFieldsByTags 将src中srcTagKey的tag名与dst中dstTagKey的tag名相同的字段复制到dst中,
+// 如src用json tag, dst用db tag. 返回匹配到的字段 dst字段名=>src字段名
+// 出错时继续复制其余字段, 返回包含所有错误的*MultiError
+func CopyFieldsByTags(dst, src interface{}, dstTagKey, srcTagKey string) (map[string]string, error) {
+	return defaultMapper.copyFieldsByTags(dst, src, dstTagKey, srcTagKey)
+}
+
+func (m *Mapper) copyFieldsByTags(dst, src interface{}, dstTagKey, srcTagKey string) (pairs map[string]string, err error) {
+	pairs, err = MatchFieldsByTags(dst, src, dstTagKey, srcTagKey)
+	if err != nil {
+		return nil, err
+	}
+
+	srcItems, err := ItemsDeep(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs MultiError
+	for _, dstName := range sortedKeys(pairs) {
+		errs.add(dstName, m.setField(dst, dstName, srcItems[pairs[dstName]]))
+	}
+
+	return pairs, errs.errOrNil()
+}
+
+// MatchFieldsByTags 返回dst与src中tag名相同的字段 dst字段名=>src字段名
+// 没有tag或tag为"-"的字段不参与匹配
+func MatchFieldsByTags(dst, src interface{}, dstTagKey, srcTagKey string) (map[string]string, error) {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return nil, errors.New("dst must be a pointer to struct")
+	}
+
+	dstTags, err := structFieldNamesAndTags(dst, dstTagKey, true, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	srcTags, err := structFieldNamesAndTags(src, srcTagKey, true, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string)
+	for tagname, dstName := range dstTags {
+		if tagname == "" || tagname == "-" {
+			continue
+		}
+		if srcName, ok := srcTags[tagname]; ok {
+			pairs[dstName] = srcName
+		}
+	}
+
+	return pairs, nil
+}
+
+// SetFieldsFromStruct 只将fieldNames中列出的字段从src复制到dst, 使用SetField的类型转换,
+// 用于只应用请求中允许修改的字段. fieldNames中有src或dst没有的字段时返回错误, 不做任何修改
+func SetFieldsFromStruct(dst, src interface{}, fieldNames []string) error {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) {
+		return errors.New("dst must be a pointer to struct")
+	}
+
+	values := make([]interface{}, len(fieldNames))
+	for i, name := range fieldNames {
+		if ok, _ := HasField(dst, name); !ok {
+			return fmt.Errorf("no such field: %s in dst", name)
+		}
+		value, errGet := GetField(src, name)
+		if errGet != nil {
+			return errGet
+		}
+		values[i] = value
+	}
+
+	return SetFields(dst, fieldNames, values)
+}
+
+// MapToStruct 将map中的值设置到obj(struct指针)中, key按字段名匹配, 没有同名字段时按json tag名匹配,
+// 用于解码后的json对象. 使用SetField的类型转换, 没有对应字段的key忽略
+// 出错时继续设置其余字段, 返回包含所有错误的*MultiError
+func MapToStruct(obj interface{}, values map[string]interface{}) error {
+	return defaultMapper.mapToStruct(obj, values)
+}
+
+func (m *Mapper) mapToStruct(obj interface{}, values map[string]interface{}) (err error) {
+	structValue, err := ReflectValueSettable(obj)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	var jsonFields map[string]string
+	structType := structValue.Type()
+	for _, key := range sortedKeys(values) {
+		name := key
+		if field, ok := structType.FieldByName(key); !ok || !IsExportableField(field) {
+			if jsonFields == nil {
+				jsonFields, _ = structFieldNamesAndTags(obj, "json", true, false, nil)
+			}
+			if name, ok = jsonFields[key]; !ok || key == "" || key == "-" {
+				continue
+			}
+		}
+
+		errs.add(name, m.setStructField(structValue, name, values[key]))
+	}
+
+	return errs.errOrNil()
+}
@@ -0,0 +1,77 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCopyFieldsErrorOrder(t *testing.T) {
+	type src struct {
+		D string
+		A string
+		C string
+		B string
+	}
+	type dst struct {
+		A int
+		B int
+		C int
+		D int
+	}
+
+	want := []string{"A", "B", "C", "D"}
+	for i := 0; i < 20; i++ {
+		err := CopyFields(&dst{}, src{A: "a", B: "b", C: "c", D: "d"})
+		if got := multiErrorFields(t, err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("CopyFields error fields = %v, want %v", got, want)
+		}
+
+		err = ScanMap(&dst{}, map[string]interface{}{"D": "d", "C": "c", "B": "b", "A": "a"}, "db")
+		if got := multiErrorFields(t, err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("ScanMap error fields = %v, want %v", got, want)
+		}
+	}
+}
+
+func multiErrorFields(t *testing.T, err error) []string {
+	t.Helper()
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("error %v is not a *MultiError", err)
+	}
+	fields := make([]string, len(multi.Errors))
+	for i, fieldErr := range multi.Errors {
+		var fe *FieldError
+		if !errors.As(fieldErr, &fe) {
+			t.Fatalf("error %v is not a *FieldError", fieldErr)
+		}
+		fields[i] = fe.Field
+	}
+	return fields
+}
+
+func TestConvertWithMappingNestedStructs(t *testing.T) {
+	type inA struct {
+		V int
+	}
+	type inB struct {
+		V int64
+	}
+	type src struct {
+		N    inA
+		Name string
+	}
+	type dst struct {
+		N     inB
+		Title string
+	}
+
+	var d dst
+	if err := ConvertWithMapping(&d, src{N: inA{V: 3}, Name: "n"}, map[string]string{"Name": "Title"}); err != nil {
+		t.Fatal(err)
+	}
+	if d.N.V != 3 || d.Title != "n" {
+		t.Errorf("ConvertWithMapping() = %+v", d)
+	}
+}
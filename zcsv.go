@@ -0,0 +1,111 @@
+package ygrpcgoutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// StructCSVHeader returns the csv column names of obj in declaration order, the column name is
+// the tagKey tag name of the field or the field name when it has no such tag, "-" tagged fields are skipped.
+// anonymous structs without tag are flattened, their fields become columns in place.
+func StructCSVHeader(obj interface{}, tagKey string) ([]string, error) {
+	var header []string
+	err := eachCSVColumn(obj, tagKey, func(column string, _ reflect.Value) error {
+		header = append(header, column)
+		return nil
+	})
+	return header, err
+}
+
+// StructCSVRow returns the csv values of obj in the same order as StructCSVHeader.
+// time.Time is formatted with FormatISO, []byte as string, nil pointers as "",
+// other structs, maps and slices are json encoded in one column.
+func StructCSVRow(obj interface{}, tagKey string) ([]string, error) {
+	var row []string
+	err := eachCSVColumn(obj, tagKey, func(column string, value reflect.Value) error {
+		s, err := csvValue(value)
+		if err != nil {
+			return fmt.Errorf("cannot format column %s: %w", column, err)
+		}
+		row = append(row, s)
+		return nil
+	})
+	return row, err
+}
+
+func eachCSVColumn(obj interface{}, tagKey string, fn func(column string, value reflect.Value) error) error {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return errors.New("cannot use StructCSV on a non-struct interface")
+	}
+
+	return eachCSVStructColumn(ReflectValue(obj), tagKey, fn)
+}
+
+func eachCSVStructColumn(objValue reflect.Value, tagKey string, fn func(column string, value reflect.Value) error) error {
+	objType := objValue.Type()
+
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+
+		tagname := ""
+		if tagKey != "" {
+			tagname = tagValueName(field.Tag.Get(tagKey))
+		}
+		if tagname == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := eachCSVStructColumn(objValue.Field(i), tagKey, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		column := field.Name
+		if tagname != "" {
+			column = tagname
+		}
+		if column == "-" {
+			continue
+		}
+		if err := fn(column, objValue.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func csvValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return FormatISO(v.Interface().(time.Time)), nil
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	case reflect.Map, reflect.Array:
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
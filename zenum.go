@@ -0,0 +1,65 @@
+package ygrpcgoutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+var (
+	enumNamesMu sync.RWMutex
+	enumNames   = make(map[reflect.Type]map[int32]string)
+	enumValues  = make(map[reflect.Type]map[string]int32)
+)
+
+// RegisterEnumNames 注册enum类型(int32)的名字表, SetField将此类型的值设置到string字段时使用名字,
+// 名字表中没有的值使用数字字符串. 反过来string设置到此类型的字段时按名字查找值(也接受数字字符串). 一般在init中调用, 如
+// RegisterEnumNames(reflect.TypeOf(pb.Status(0)), pb.Status_name)
+func RegisterEnumNames(enumType reflect.Type, names map[int32]string) {
+	namesCopy := make(map[int32]string, len(names))
+	values := make(map[string]int32, len(names))
+	for v, name := range names {
+		namesCopy[v] = name
+		values[name] = v
+	}
+
+	enumNamesMu.Lock()
+	defer enumNamesMu.Unlock()
+	enumNames[enumType] = namesCopy
+	enumValues[enumType] = values
+}
+
+// enumName returns the registered name of enum value v, ok is false when enumType is not registered
+func enumName(enumType reflect.Type, v int32) (name string, ok bool) {
+	enumNamesMu.RLock()
+	defer enumNamesMu.RUnlock()
+
+	names, ok := enumNames[enumType]
+	if !ok {
+		return "", false
+	}
+	if name, found := names[v]; found {
+		return name, true
+	}
+	return strconv.Itoa(int(v)), true
+}
+
+// enumValue returns the value of the registered enum name, numeric strings are accepted as well.
+// ok is false when enumType is not registered, err is set for an unknown name
+func enumValue(enumType reflect.Type, name string) (v int32, ok bool, err error) {
+	enumNamesMu.RLock()
+	defer enumNamesMu.RUnlock()
+
+	values, ok := enumValues[enumType]
+	if !ok {
+		return 0, false, nil
+	}
+	if v, found := values[name]; found {
+		return v, true, nil
+	}
+	if n, err := strconv.ParseInt(name, 10, 32); err == nil {
+		return int32(n), true, nil
+	}
+	return 0, true, fmt.Errorf("unknown %s enum name %q", enumType.String(), name)
+}
@@ -0,0 +1,65 @@
+package ygrpcgoutil
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// TagKeyEnv is the default tag naming the environment variable of a field for SetFieldsFromEnv
+const TagKeyEnv = "env"
+
+// SetFieldsFromEnv sets every field of obj tagged with tagKey(TagKeyEnv for "") like `env:"DB_PORT"`
+// from that environment variable, converting the string with the SetField rules plus number and bool
+// text("8080" into int, "true" into bool, "5s" into time.Duration...), fractional text like "1.5" into
+// integer fields is an error. numeric text wins over the code point rule of SetField, so "4" into an
+// int32 or uint8 field is 4. field tags like `ygutil:"-"` are honored as by SetField.
+// fields of nested and anonymous structs are set as well.
+// unset variables leave the field unchanged, so do empty ones unless tagged `env:"NAME,allowempty"`.
+// every value that can not be set is reported in the returned *MultiError. obj must be a pointer to struct
+func SetFieldsFromEnv(obj interface{}, tagKey string) error {
+	if tagKey == "" {
+		tagKey = TagKeyEnv
+	}
+	structValue, err := ReflectValueSettable(obj)
+	if err != nil {
+		return err
+	}
+
+	envMapper := *defaultMapper
+	envMapper.parseStrings = true
+
+	var errs MultiError
+	envMapper.setFieldsFromEnv(structValue, "", tagKey, &errs)
+
+	return errs.errOrNil()
+}
+
+// setFieldsFromEnv sets the env tagged fields of structValue with setStructField, so field tags like
+// `ygutil:"-"` apply, and descends nested structs like WalkStruct. prefix is the path of structValue
+func (m *Mapper) setFieldsFromEnv(structValue reflect.Value, prefix string, tagKey string, errs *MultiError) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+		path := prefix + field.Name
+
+		tag := ParseTag(field.Tag.Get(tagKey))
+		if tag.Name == "" || tag.Name == "-" {
+			if !isLeafField(field) {
+				m.setFieldsFromEnv(structValue.Field(i), path+".", tagKey, errs)
+			}
+			continue
+		}
+
+		envValue, ok := os.LookupEnv(tag.Name)
+		if !ok || (envValue == "" && !tag.Has("allowempty")) {
+			continue
+		}
+		if err := m.setStructField(structValue, field.Name, envValue); err != nil {
+			errs.add(path, fmt.Errorf("env %s: %w", tag.Name, err))
+		}
+	}
+}
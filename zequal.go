@@ -0,0 +1,140 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// StructEqual compares the exportable fields of a and b, which must be the same struct type
+// (or pointers to it). time.Time and *time.Time fields are equal when they differ by at most
+// timeTolerance, nested structs holding time fields are compared the same way, other fields
+// (other structs included) use reflect.DeepEqual.
+// use it for structs round-tripped through storage that truncates timestamps
+func StructEqual(a, b interface{}, timeTolerance time.Duration) (bool, error) {
+	if !hasValidType(a, []reflect.Kind{reflect.Struct, reflect.Ptr}) || !hasValidType(b, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return false, errors.New("cannot use StructEqual on a non-struct interface")
+	}
+
+	aValue := ReflectValue(a)
+	bValue := ReflectValue(b)
+	if aValue.Type() != bValue.Type() {
+		return false, errors.New("a and b must be the same struct type")
+	}
+
+	return structValuesEqual(aValue, bValue, timeTolerance), nil
+}
+
+func structValuesEqual(a, b reflect.Value, timeTolerance time.Duration) bool {
+	for i := 0; i < a.NumField(); i++ {
+		if !IsExportableField(a.Type().Field(i)) {
+			continue
+		}
+		if !valuesEqual(a.Field(i), b.Field(i), timeTolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func valuesEqual(a, b reflect.Value, timeTolerance time.Duration) bool {
+	switch {
+	case a.Type() == timeType:
+		d := a.Interface().(time.Time).Sub(b.Interface().(time.Time))
+		if d < 0 {
+			d = -d
+		}
+		return d <= timeTolerance
+	case a.Kind() == reflect.Ptr && a.Type().Elem() == timeType:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return valuesEqual(a.Elem(), b.Elem(), timeTolerance)
+	case a.Kind() == reflect.Struct && hasTimeField(a.Type(), map[reflect.Type]bool{}):
+		return structValuesEqual(a, b, timeTolerance)
+	default:
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
+
+// hasTimeField reports whether the struct type t has an exportable time field(see isTimeType),
+// directly or in exportable nested struct fields. structs without one, like netip.Addr whose
+// state is unexported, are compared with reflect.DeepEqual instead of field by field
+func hasTimeField(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+		if isTimeType(field.Type) || (field.Type.Kind() == reflect.Struct && hasTimeField(field.Type, seen)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffStructs returns the names of the fields whose values differ between a and b
+// in FieldsDeep order, values are compared with reflect.DeepEqual.
+// a and b must be the same struct type (or pointers to it)
+func DiffStructs(a, b interface{}) ([]string, error) {
+	if !hasValidType(a, []reflect.Kind{reflect.Struct, reflect.Ptr}) || !hasValidType(b, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use DiffStructs on a non-struct interface")
+	}
+	if ReflectValue(a).Type() != ReflectValue(b).Type() {
+		return nil, errors.New("a and b must be the same struct type")
+	}
+
+	names, err := FieldsDeep(a)
+	if err != nil {
+		return nil, err
+	}
+	aItems, err := ItemsDeep(a)
+	if err != nil {
+		return nil, err
+	}
+	bItems, err := ItemsDeep(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, name := range names {
+		if !reflect.DeepEqual(aItems[name], bItems[name]) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed, nil
+}
+
+// FieldIn reports whether the name field of obj equals one of allowed, the allowed values are
+// converted to the field type with ConvertValue first so literals like 1 or "ACTIVE" can be passed.
+// an allowed value that can not be converted is an error
+func FieldIn(obj interface{}, name string, allowed ...interface{}) (bool, error) {
+	fieldValue, err := FieldValue(obj, name)
+	if err != nil {
+		return false, err
+	}
+	if !fieldValue.CanInterface() {
+		return false, fmt.Errorf("cannot use FieldIn on non-exported field %s", name)
+	}
+	value := fieldValue.Interface()
+
+	for _, a := range allowed {
+		converted, err := ConvertValue(a, fieldValue.Type())
+		if err != nil {
+			return false, fmt.Errorf("%s: cannot convert allowed value %v: %w", name, a, err)
+		}
+		if reflect.DeepEqual(value, converted) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,46 @@
+package ygrpcgoutil
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestStructEqualNestedUnexportedState(t *testing.T) {
+	type S struct {
+		A netip.Addr
+		B int
+	}
+
+	equal, err := StructEqual(
+		S{A: netip.MustParseAddr("1.1.1.1"), B: 1},
+		S{A: netip.MustParseAddr("2.2.2.2"), B: 1},
+		time.Second,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if equal {
+		t.Error("structs with different netip.Addr fields compared equal")
+	}
+}
+
+func TestStructEqualNestedTimeTolerance(t *testing.T) {
+	type Inner struct {
+		At time.Time
+	}
+	type S struct {
+		Inner Inner
+		Addr  netip.Addr
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+	addr := netip.MustParseAddr("1.1.1.1")
+	equal, err := StructEqual(S{Inner: Inner{At: now}, Addr: addr}, S{Inner: Inner{At: now.Truncate(time.Second)}, Addr: addr}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal {
+		t.Error("nested time within tolerance compared unequal")
+	}
+}
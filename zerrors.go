@@ -0,0 +1,76 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldError is the error of one field in a MultiError, Field is the field name(or dotted path
+// for nested fields). the message is the one of Err, which names the field already
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError collects every failure of the functions setting many fields(SetFields, CopyFields,
+// MapToStruct...), which keep going after a failed field. errors.Is and errors.As match each error.
+// the errors of the functions reading maps are ordered by field name or column name, not by map order
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// add records err of the field, nil err is ignored
+func (e *MultiError) add(field string, err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, &FieldError{Field: field, Err: err})
+	}
+}
+
+// errOrNil returns e when it holds errors, nil otherwise
+func (e *MultiError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// FieldErr returns the first error in err(a MultiError, FieldError or an error wrapping them)
+// concerning the field, nil when there is none
+func FieldErr(err error, field string) error {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) && fieldErr.Field == field {
+		return fieldErr
+	}
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range wrapped.Unwrap() {
+			if found := FieldErr(e, field); found != nil {
+				return found
+			}
+		}
+	case interface{ Unwrap() error }:
+		return FieldErr(wrapped.Unwrap(), field)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// GetString returns the name field of obj converted to string with the SetField conversion rules,
+// numbers and bools are formatted with strconv like 42, 1.5 or true
+func GetString(obj interface{}, name string) (string, error) {
+	return getFieldAs[string](obj, name)
+}
+
+// GetInt64 returns the name field of obj converted to int64 with the SetField conversion rules,
+// numeric text like "42" is parsed as well
+func GetInt64(obj interface{}, name string) (int64, error) {
+	return getFieldAs[int64](obj, name)
+}
+
+// GetBool returns the name field of obj converted to bool with the SetField conversion rules,
+// bool text like "true" is parsed as well
+func GetBool(obj interface{}, name string) (bool, error) {
+	return getFieldAs[bool](obj, name)
+}
+
+// GetFloat64 returns the name field of obj converted to float64 with the SetField conversion rules,
+// numeric text like "1.5" is parsed as well
+func GetFloat64(obj interface{}, name string) (float64, error) {
+	return getFieldAs[float64](obj, name)
+}
+
+// GetTime returns the name field of obj converted to time.Time with the SetField conversion rules
+func GetTime(obj interface{}, name string) (time.Time, error) {
+	return getFieldAs[time.Time](obj, name)
+}
+
+// GetFieldSlice returns the elements of the slice or array name field of obj as []interface{},
+// a nil slice returns an empty slice. other field kinds are an error
+func GetFieldSlice(obj interface{}, name string) ([]interface{}, error) {
+	fieldValue, err := FieldValue(obj, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind := fieldValue.Kind(); kind != reflect.Slice && kind != reflect.Array {
+		return nil, fmt.Errorf("field %s is %s, want slice or array", name, fieldValue.Type().String())
+	}
+	if !fieldValue.CanInterface() {
+		return nil, fmt.Errorf("cannot get non-exported field %s", name)
+	}
+
+	elems := make([]interface{}, fieldValue.Len())
+	for i := range elems {
+		elems[i] = fieldValue.Index(i).Interface()
+	}
+
+	return elems, nil
+}
+
+// getterMapper converts for the GetXxx accessors, numeric and bool text is parsed and nothing is logged
+var getterMapper = func() *Mapper {
+	m := NewMapper(WithLogger(nil))
+	m.parseStrings = true
+	return m
+}()
+
+func getFieldAs[T any](obj interface{}, name string) (T, error) {
+	var zero T
+
+	value, err := GetField(obj, name)
+	if err != nil {
+		return zero, err
+	}
+
+	target := reflect.TypeOf(zero)
+	if s, ok := formatPlainNumber(value); target.Kind() == reflect.String && ok {
+		return reflect.ValueOf(s).Convert(target).Interface().(T), nil
+	}
+
+	converted, err := getterMapper.convertValue(reflect.ValueOf(value), reflect.StructField{Name: name, Type: target}, target)
+	if err != nil {
+		return zero, fmt.Errorf("cannot get field %s as %s: %w", name, target.String(), err)
+	}
+	if !converted.IsValid() {
+		return zero, nil
+	}
+
+	return converted.Interface().(T), nil
+}
+
+// formatPlainNumber formats value of a predeclared number or bool type with strconv,
+// named types like enums or time.Duration are left to the conversion rules
+func formatPlainNumber(value interface{}) (string, bool) {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() || val.Type().PkgPath() != "" || val.Type().Name() == "" {
+		return "", false
+	}
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 64), true
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), true
+	}
+	return "", false
+}
+
+// ForEachFieldOfType calls fn with every exportable field(FieldsDeepOrdered order) whose type is
+// assignable to T, like all time.Time fields or all fields implementing fmt.Stringer.
+// fields promoted through nil embedded pointers are skipped, an error returned by fn stops the iteration.
+// obj can whether be a structure or pointer to structure
+func ForEachFieldOfType[T any](obj interface{}, fn func(name string, value T) error) error {
+	return eachFieldOfType[T](obj, false, func(name string, fieldValue reflect.Value) error {
+		//nil interface fields give the zero T
+		value, _ := fieldValue.Interface().(T)
+		return fn(name, value)
+	})
+}
+
+// ForEachFieldPtrOfType is ForEachFieldOfType for changing fields, fn gets a pointer to every
+// field whose type is exactly T. obj must be a pointer to struct
+func ForEachFieldPtrOfType[T any](obj interface{}, fn func(name string, ptr *T) error) error {
+	if !hasValidType(obj, []reflect.Kind{reflect.Ptr}) {
+		return errors.New("obj must be a non nil pointer to struct")
+	}
+
+	return eachFieldOfType[T](obj, true, func(name string, fieldValue reflect.Value) error {
+		return fn(name, fieldValue.Addr().Interface().(*T))
+	})
+}
+
+func eachFieldOfType[T any](obj interface{}, exact bool, fn func(name string, fieldValue reflect.Value) error) error {
+	names, err := FieldsDeepOrdered(obj)
+	if err != nil {
+		return err
+	}
+
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	objValue := ReflectValue(obj)
+	for _, name := range names {
+		field, ok := objValue.Type().FieldByName(name)
+		if !ok {
+			//ambiguous promoted name
+			continue
+		}
+		if field.Type != target && (exact || !field.Type.AssignableTo(target)) {
+			continue
+		}
+		fieldValue, err := objValue.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+		if exact && !fieldValue.CanSet() {
+			continue
+		}
+		if err := fn(name, fieldValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MapStructFields replaces every exportable field(FieldsDeepOrdered order) of obj with
+// transform(name, value), written back with SetField, like trimming or masking all fields at once.
+// an error of transform or SetField stops and is returned with the field name. obj must be a pointer to struct
+func MapStructFields(obj interface{}, transform func(name string, value interface{}) (interface{}, error)) error {
+	if _, err := ReflectValueSettable(obj); err != nil {
+		return err
+	}
+
+	names, err := FieldsDeepOrdered(obj)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fieldValue, err := FieldValue(obj, name)
+		if err != nil {
+			//promoted through a nil embedded pointer
+			continue
+		}
+
+		value, err := transform(name, fieldValue.Interface())
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := SetField(obj, name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package ygrpcgoutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetters(t *testing.T) {
+	type config struct {
+		Port    int
+		Workers int32
+		Size    uint32
+		Ratio   float64
+		Debug   bool
+		Limit   string
+		Enabled string
+		Scale   string
+		Timeout time.Duration
+	}
+	c := config{Port: 8080, Workers: 4, Size: 7, Ratio: 1.5, Debug: true, Limit: "42", Enabled: "true", Scale: "2.5", Timeout: 5 * time.Second}
+
+	var logged []string
+	logger := defaultMapper.logger
+	defaultMapper.logger = func(format string, args ...interface{}) { logged = append(logged, format) }
+	defer func() { defaultMapper.logger = logger }()
+
+	for name, want := range map[string]string{"Port": "8080", "Workers": "4", "Size": "7", "Ratio": "1.5", "Debug": "true"} {
+		if got, err := GetString(c, name); err != nil || got != want {
+			t.Errorf("GetString(%s) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if got, err := GetInt64(c, "Limit"); err != nil || got != 42 {
+		t.Errorf("GetInt64(Limit) = %d, %v, want 42", got, err)
+	}
+	if got, err := GetBool(c, "Enabled"); err != nil || !got {
+		t.Errorf("GetBool(Enabled) = %v, %v, want true", got, err)
+	}
+	if got, err := GetFloat64(c, "Scale"); err != nil || got != 2.5 {
+		t.Errorf("GetFloat64(Scale) = %v, %v, want 2.5", got, err)
+	}
+	if got, err := GetInt64(c, "Timeout"); err != nil || got != int64(5*time.Second) {
+		t.Errorf("GetInt64(Timeout) = %v, %v", got, err)
+	}
+	if len(logged) > 0 {
+		t.Errorf("getters logged %v", logged)
+	}
+}
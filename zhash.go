@@ -0,0 +1,174 @@
+package ygrpcgoutil
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// HashStruct returns a deterministic FNV-64a hash over the exportable field names and values of obj,
+// fields are walked like ItemsDeep in declaration order. obj can whether be a structure or pointer to structure.
+//
+// supported kinds: bool, numbers, string, slice, array, map, struct, pointer and interface.
+// map entries are hashed independent of iteration order, nil and empty slices/maps hash the same,
+// pointers hash the pointed value, time.Time hashes the instant(location is ignored), nested structs
+// hash their exportable fields, structs without exportable fields like netip.Addr or big.Int hash
+// their binary or text encoding. func, chan and unsafe.Pointer values return an error.
+// cyclic pointer graphs are not supported, see IsCyclicType.
+func HashStruct(obj interface{}) (uint64, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return 0, errors.New("cannot use HashStruct on a non-struct interface")
+	}
+
+	h := fnv.New64a()
+	if err := hashStructFields(h, ReflectValue(obj)); err != nil {
+		return 0, err
+	}
+
+	return h.Sum64(), nil
+}
+
+func hashStructFields(h hash.Hash64, objValue reflect.Value) error {
+	objType := objValue.Type()
+
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+
+		if field.Anonymous && isNestedStructType(field.Type) {
+			if err := hashStructFields(h, objValue.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		hashString(h, field.Name)
+		if err := hashValue(h, objValue.Field(i)); err != nil {
+			return fmt.Errorf("cannot hash field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func hashValue(h hash.Hash64, v reflect.Value) error {
+	var buf [8]byte
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf[:], u)
+		h.Write(buf[:])
+	}
+
+	if !v.IsValid() {
+		h.Write([]byte{0})
+		return nil
+	}
+	h.Write([]byte{byte(v.Kind())})
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeUint(math.Float64bits(real(c)))
+		writeUint(math.Float64bits(imag(c)))
+	case reflect.String:
+		hashString(h, v.String())
+	case reflect.Slice, reflect.Array:
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := hashValue(h, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		entries := make([]uint64, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := fnv.New64a()
+			if err := hashValue(entry, iter.Key()); err != nil {
+				return err
+			}
+			if err := hashValue(entry, iter.Value()); err != nil {
+				return err
+			}
+			entries = append(entries, entry.Sum64())
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i] < entries[j] })
+		writeUint(uint64(len(entries)))
+		for _, entry := range entries {
+			writeUint(entry)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+			return nil
+		}
+		writeUint(1)
+		if v.Kind() == reflect.Interface {
+			hashString(h, v.Elem().Type().String())
+		}
+		return hashValue(h, v.Elem())
+	case reflect.Struct:
+		if v.Type() == timeType && v.CanInterface() {
+			//UnixNano is undefined outside 1678-2262, hash seconds and nanoseconds apart
+			t := v.Interface().(time.Time)
+			writeUint(uint64(t.Unix()))
+			writeUint(uint64(t.Nanosecond()))
+			return nil
+		}
+		if !isNestedStructType(v.Type()) && v.CanInterface() {
+			//netip.Addr, big.Int... keep their state unexported, hash their encoding
+			b, err := opaqueStructBytes(v)
+			if err != nil {
+				return err
+			}
+			hashString(h, string(b))
+			return nil
+		}
+		return hashStructFields(h, v)
+	default:
+		return fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+
+	return nil
+}
+
+// opaqueStructBytes encodes the struct v whose state is unexported with its BinaryMarshaler or
+// TextMarshaler, value or pointer receiver, falling back to its fmt %v formatting
+func opaqueStructBytes(v reflect.Value) ([]byte, error) {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	switch marshaler := ptr.Interface().(type) {
+	case encoding.BinaryMarshaler:
+		return marshaler.MarshalBinary()
+	case encoding.TextMarshaler:
+		return marshaler.MarshalText()
+	}
+	return []byte(fmt.Sprintf("%v", v.Interface())), nil
+}
+
+func hashString(h hash.Hash64, s string) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(len(s)))
+	h.Write(buf[:])
+	h.Write([]byte(s))
+}
@@ -0,0 +1,41 @@
+package ygrpcgoutil
+
+import (
+	"math/big"
+	"net/netip"
+	"testing"
+)
+
+func TestHashStructOpaqueStructs(t *testing.T) {
+	type host struct {
+		Name  string
+		IP    netip.Addr
+		Price big.Int
+	}
+
+	hashOf := func(h host) uint64 {
+		t.Helper()
+		sum, err := HashStruct(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sum
+	}
+
+	a := host{Name: "h", IP: netip.MustParseAddr("10.0.0.1")}
+	b := host{Name: "h", IP: netip.MustParseAddr("10.0.0.2")}
+	if hashOf(a) == hashOf(b) {
+		t.Errorf("hosts differing by IP hash the same")
+	}
+
+	c := a
+	c.Price.SetInt64(7)
+	if hashOf(a) == hashOf(c) {
+		t.Errorf("hosts differing by Price hash the same")
+	}
+
+	d := host{Name: "h", IP: netip.MustParseAddr("10.0.0.1")}
+	if hashOf(a) != hashOf(d) {
+		t.Errorf("equal hosts hash differently")
+	}
+}
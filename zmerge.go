@@ -0,0 +1,120 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"reflect"
+)
+
+// SliceMergeMode selects how DeepMergeWithMode merges slice fields
+type SliceMergeMode int
+
+const (
+	// SliceMergeReplace replaces the dst slice with a non empty patch slice
+	SliceMergeReplace SliceMergeMode = iota
+	// SliceMergeAppend appends the patch slice elements to the dst slice
+	SliceMergeAppend
+)
+
+// MergeStructs 将patch中非零值的导出字段覆盖到dst中, dst必须是struct指针,
+// patch是同类型的struct或struct指针. 嵌套的struct/map作为整体覆盖
+func MergeStructs(dst, patch interface{}) error {
+	dstValue, patchValue, err := mergeValues(dst, patch)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < dstValue.NumField(); i++ {
+		fieldValue := dstValue.Field(i)
+		patchField := patchValue.Field(i)
+		if fieldValue.CanSet() && !patchField.IsZero() {
+			fieldValue.Set(patchField)
+		}
+	}
+
+	return nil
+}
+
+// DeepMerge 与MergeStructs相同, 但嵌套的struct, struct指针和map会递归合并而不是整体覆盖,
+// time.Time, netip.Addr, big.Int等状态未导出的struct整体覆盖.
+// 非空的slice会整体替换, 需要追加时使用DeepMergeWithMode
+func DeepMerge(dst, patch interface{}) error {
+	return DeepMergeWithMode(dst, patch, SliceMergeReplace)
+}
+
+// DeepMergeWithMode 与DeepMerge相同, sliceMode选择slice字段替换还是追加
+func DeepMergeWithMode(dst, patch interface{}, sliceMode SliceMergeMode) error {
+	dstValue, patchValue, err := mergeValues(dst, patch)
+	if err != nil {
+		return err
+	}
+
+	deepMergeValue(dstValue, patchValue, sliceMode)
+	return nil
+}
+
+func mergeValues(dst, patch interface{}) (reflect.Value, reflect.Value, error) {
+	if !hasValidType(dst, []reflect.Kind{reflect.Ptr}) || !hasValidType(patch, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return reflect.Value{}, reflect.Value{}, errors.New("dst must be a pointer to struct and patch a struct")
+	}
+
+	dstValue := ReflectValue(dst)
+	patchValue := ReflectValue(patch)
+	if dstValue.Kind() != reflect.Struct || dstValue.Type() != patchValue.Type() {
+		return reflect.Value{}, reflect.Value{}, errors.New("dst and patch must be the same struct type")
+	}
+
+	return dstValue, patchValue, nil
+}
+
+// deepMergeValue merges patch into the settable dst, zero patch values are ignored
+func deepMergeValue(dst, patch reflect.Value, sliceMode SliceMergeMode) {
+	if patch.IsZero() || !dst.CanSet() {
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		if !isNestedStructType(dst.Type()) {
+			//time.Time, netip.Addr, big.Int... are set as a whole, their state is unexported
+			dst.Set(patch)
+			return
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			deepMergeValue(dst.Field(i), patch.Field(i), sliceMode)
+		}
+	case reflect.Ptr:
+		if !isNestedStructType(dst.Type().Elem()) {
+			dst.Set(patch)
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		deepMergeValue(dst.Elem(), patch.Elem(), sliceMode)
+	case reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), patch.Len()))
+		}
+		elemType := dst.Type().Elem()
+		iter := patch.MapRange()
+		for iter.Next() {
+			key, patchElem := iter.Key(), iter.Value()
+			dstElem := dst.MapIndex(key)
+			if dstElem.IsValid() && (elemType.Kind() == reflect.Struct || elemType.Kind() == reflect.Map) {
+				merged := reflect.New(elemType).Elem()
+				merged.Set(dstElem)
+				deepMergeValue(merged, patchElem, sliceMode)
+				patchElem = merged
+			}
+			dst.SetMapIndex(key, patchElem)
+		}
+	case reflect.Slice:
+		if sliceMode == SliceMergeAppend {
+			dst.Set(reflect.AppendSlice(dst, patch))
+		} else {
+			dst.Set(patch)
+		}
+	default:
+		dst.Set(patch)
+	}
+}
@@ -0,0 +1,26 @@
+package ygrpcgoutil
+
+import (
+	"math/big"
+	"net/netip"
+	"testing"
+)
+
+func TestDeepMergeOpaqueStructs(t *testing.T) {
+	type host struct {
+		Name  string
+		IP    netip.Addr
+		Price big.Int
+		Limit *big.Int
+	}
+
+	dst := host{Name: "a"}
+	patch := host{IP: netip.MustParseAddr("10.0.0.1"), Limit: big.NewInt(5)}
+	patch.Price.SetInt64(3)
+	if err := DeepMerge(&dst, patch); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "a" || dst.IP != patch.IP || dst.Price.Int64() != 3 || dst.Limit == nil || dst.Limit.Int64() != 5 {
+		t.Errorf("DeepMerge() = %+v", dst)
+	}
+}
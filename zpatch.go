@@ -0,0 +1,103 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONPatch returns RFC6902 style operations turning a into b, like
+// {"op": "replace", "path": "/Address/City", "value": "Paris"}, for the leaf fields(see LeafPaths)
+// that differ. map fields produce "add"/"remove"/"replace" operations per key, slices, arrays
+// and pointers are replaced as a whole. paths use field names, a and b must be the same struct type
+func JSONPatch(a, b interface{}) ([]map[string]interface{}, error) {
+	if !hasValidType(a, []reflect.Kind{reflect.Struct, reflect.Ptr}) || !hasValidType(b, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use JSONPatch on a non-struct interface")
+	}
+	if ReflectValue(a).Type() != ReflectValue(b).Type() {
+		return nil, errors.New("a and b must be the same struct type")
+	}
+
+	aLeaves := make(map[string]reflect.Value)
+	err := WalkStruct(a, func(path string, field reflect.StructField, value reflect.Value) error {
+		if isLeafField(field) {
+			aLeaves[path] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []map[string]interface{}
+	err = WalkStruct(b, func(path string, field reflect.StructField, bValue reflect.Value) error {
+		if !isLeafField(field) {
+			return nil
+		}
+		aValue := aLeaves[path]
+		pointer := jsonPointer(path)
+
+		if field.Type.Kind() == reflect.Map && !aValue.IsNil() && !bValue.IsNil() {
+			ops = append(ops, mapPatch(pointer, aValue, bValue)...)
+			return nil
+		}
+		if !reflect.DeepEqual(aValue.Interface(), bValue.Interface()) {
+			ops = append(ops, patchOp("replace", pointer, bValue.Interface()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ops, nil
+}
+
+// mapPatch returns the operations turning map a into map b, in key order
+func mapPatch(pointer string, a, b reflect.Value) []map[string]interface{} {
+	keys := make(map[string]reflect.Value)
+	for _, key := range append(a.MapKeys(), b.MapKeys()...) {
+		keys[fmt.Sprint(key.Interface())] = key
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ops []map[string]interface{}
+	for _, name := range names {
+		key := keys[name]
+		keyPointer := pointer + "/" + escapeJSONPointer(name)
+		aElem, bElem := a.MapIndex(key), b.MapIndex(key)
+		switch {
+		case !aElem.IsValid():
+			ops = append(ops, patchOp("add", keyPointer, bElem.Interface()))
+		case !bElem.IsValid():
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": keyPointer})
+		case !reflect.DeepEqual(aElem.Interface(), bElem.Interface()):
+			ops = append(ops, patchOp("replace", keyPointer, bElem.Interface()))
+		}
+	}
+	return ops
+}
+
+func patchOp(op, path string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"op": op, "path": path, "value": value}
+}
+
+// jsonPointer converts a dotted WalkStruct path to a json pointer, "Address.City" => "/Address/City"
+func jsonPointer(path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		parts[i] = escapeJSONPointer(part)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// escapeJSONPointer escapes "~" and "/" of a json pointer token(RFC6901)
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
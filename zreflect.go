@@ -4,23 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
-	"strings"
-	"time"
-	"unsafe"
-
-	"github.com/google/uuid"
-)
-
-const (
-	microsecondsPerSecond = 1000000
-	microsecondsPerMinute = 60 * microsecondsPerSecond
-	microsecondsPerHour   = 60 * microsecondsPerMinute
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 var WarnInt2StrInSetField = true
 
+// TagKeyYgutil is the tag key of field options of this package,
+// `ygutil:"-"` marks a field SetField/CopyFields never write
+const TagKeyYgutil = "ygutil"
+
 // GetField returns the value of the provided obj field. obj can whether
 // be a structure or pointer to structure.
 func GetField(obj interface{}, name string) (interface{}, error) {
@@ -37,6 +35,43 @@ func GetField(obj interface{}, name string) (interface{}, error) {
 	return field.Interface(), nil
 }
 
+// GetFieldByIndex returns the name and value of the i-th exportable field of obj
+// in declaration order. obj can whether be a structure or pointer to structure.
+func GetFieldByIndex(obj interface{}, i int) (name string, value interface{}, err error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return "", nil, errors.New("cannot use GetFieldByIndex on a non-struct interface")
+	}
+
+	objValue := ReflectValue(obj)
+	objType := objValue.Type()
+
+	n := 0
+	for j := 0; j < objType.NumField(); j++ {
+		field := objType.Field(j)
+		if !IsExportableField(field) {
+			continue
+		}
+		if n == i {
+			return field.Name, objValue.Field(j).Interface(), nil
+		}
+		n++
+	}
+
+	return "", nil, fmt.Errorf("field index %d out of range, obj has %d exportable fields", i, n)
+}
+
+// GetFieldOrDefault returns the value of the provided obj field, or def when
+// obj has no such field, the field is promoted through a nil embedded pointer
+// or it holds its zero value.
+func GetFieldOrDefault(obj interface{}, name string, def interface{}) interface{} {
+	field, err := FieldValue(obj, name)
+	if err != nil || !field.CanInterface() || field.IsZero() {
+		return def
+	}
+
+	return field.Interface()
+}
+
 // GetFieldKind returns the kind of the provided obj field. obj can whether
 // be a structure or pointer to structure.
 func GetFieldKind(obj interface{}, name string) (reflect.Kind, error) {
@@ -71,6 +106,17 @@ func GetFieldType(obj interface{}, name string) (string, error) {
 	return field.Type().String(), nil
 }
 
+// FieldTypeIs reports whether the type string(see GetFieldType) of the provided obj field
+// is typeName, like "time.Time" or "[]uint8". obj can whether be a structure or pointer to structure.
+func FieldTypeIs(obj interface{}, name, typeName string) (bool, error) {
+	fieldType, err := GetFieldType(obj, name)
+	if err != nil {
+		return false, err
+	}
+
+	return fieldType == typeName, nil
+}
+
 // GetFieldTag returns the provided obj field tag value. obj can whether
 // be a structure or pointer to structure.
 func GetFieldTag(obj interface{}, fieldName, tagKey string) (string, error) {
@@ -93,130 +139,411 @@ func GetFieldTag(obj interface{}, fieldName, tagKey string) (string, error) {
 	return field.Tag.Get(tagKey), nil
 }
 
+// FieldIndex returns the index path of the provided obj field, usable with
+// reflect.Value.FieldByIndex to skip repeated name lookups. obj can whether
+// be a structure or pointer to structure, promoted fields are found as well.
+func FieldIndex(obj interface{}, name string) ([]int, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use FieldIndex on a non-struct interface")
+	}
+
+	field, ok := ReflectValue(obj).Type().FieldByName(name)
+	if !ok {
+		return nil, fmt.Errorf("no such field: %s in obj", name)
+	}
+
+	return field.Index, nil
+}
+
+// FieldOrigin returns the type declaring the provided obj field and its index path, for a field
+// promoted from an embedded struct it is the embedded struct type. when embeds at the same depth
+// declare the name, the field is ambiguous and the error lists the declaring types.
+// obj can whether be a structure or pointer to structure.
+func FieldOrigin(obj interface{}, name string) (declaringType string, indexPath []int, err error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return "", nil, errors.New("cannot use FieldOrigin on a non-struct interface")
+	}
+
+	objType := ReflectValue(obj).Type()
+	field, ok := objType.FieldByName(name)
+	if !ok {
+		if declaring, _ := fieldDeclaringTypes(objType, name, 0); len(declaring) > 1 {
+			return "", nil, fmt.Errorf("ambiguous field: %s in obj, declared by %v", name, declaring)
+		}
+		return "", nil, fmt.Errorf("no such field: %s in obj", name)
+	}
+
+	declaring := objType
+	for _, i := range field.Index[:len(field.Index)-1] {
+		declaring = declaring.Field(i).Type
+		for declaring.Kind() == reflect.Ptr {
+			declaring = declaring.Elem()
+		}
+	}
+
+	return declaring.String(), field.Index, nil
+}
+
+// fieldDeclaringTypes returns the types declaring name directly at the shallowest depth
+// of typ and its embedded structs, and that depth
+func fieldDeclaringTypes(typ reflect.Type, name string, depth int) ([]string, int) {
+	if depth > 16 {
+		return nil, depth
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Name == name {
+			return []string{typ.String()}, depth
+		}
+	}
+
+	var declaring []string
+	minDepth := -1
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		embedded := field.Type
+		for embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if !field.Anonymous || embedded.Kind() != reflect.Struct {
+			continue
+		}
+
+		types, d := fieldDeclaringTypes(embedded, name, depth+1)
+		switch {
+		case len(types) == 0:
+		case minDepth < 0 || d < minDepth:
+			declaring, minDepth = types, d
+		case d == minDepth:
+			declaring = append(declaring, types...)
+		}
+	}
+	return declaring, minDepth
+}
+
+// FieldValue returns the reflect.Value of the provided obj field for reflect work the
+// helpers don't cover, it is addressable and settable(when exported) if obj is a pointer.
+// obj can whether be a structure or pointer to structure, promoted fields are found as well.
+func FieldValue(obj interface{}, name string) (reflect.Value, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return reflect.Value{}, errors.New("cannot use FieldValue on a non-struct interface")
+	}
+
+	objValue := ReflectValue(obj)
+	field, ok := objValue.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("no such field: %s in obj", name)
+	}
+
+	fieldValue, err := objValue.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot get field %s: %w", name, err)
+	}
+
+	return fieldValue, nil
+}
+
 // SetField sets the provided obj field with provided value. obj param has
 // to be a pointer to a struct, otherwise it will soundly fail. Provided
 // value type should match with the struct field you're trying to set.
+// pointer values, also chains like **int32 up to 8 deep, are dereferenced, a nil one is skipped.
+// interface typed fields accept any value implementing the interface, pointer implementations are kept as is.
+// negative numbers set into unsigned fields are an error in every mode, they used to wrap around
+// to huge values silently. other out of range numbers wrap unless the Mapper is WithStrictNumbers.
+// a single non digit character string set into a rune(int32) or byte(uint8) field is its code point, "a" sets 97,
+// bytes only take code points up to 255. numeric text like "7" or "42" is a type mismatch, only SetFieldsFromEnv reads it as a number.
 func SetField(obj interface{}, name string, value interface{}) error {
-	val := reflect.ValueOf(value)
+	return defaultMapper.setField(obj, name, value)
+}
+
+// ErrSetFieldPanic is wrapped by the error SafeSetField returns when SetField panicked
+var ErrSetFieldPanic = errors.New("set field panicked")
+
+// SafeSetField sets the obj field like SetField, but a panic of reflect on exotic field types
+// is recovered and returned as an error wrapping ErrSetFieldPanic with the panic value,
+// for request handlers that must not crash
+func SafeSetField(obj interface{}, name string, value interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %s: %v", ErrSetFieldPanic, name, r)
+		}
+	}()
+
+	return SetField(obj, name, value)
+}
+
+// FieldSetter validates obj is a pointer to struct once and returns a function
+// setting its fields like SetField, for applying many values to one obj.
+func FieldSetter(obj interface{}) (func(name string, value interface{}) error, error) {
+	structValue, err := ReflectValueSettable(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(name string, value interface{}) error {
+		return defaultMapper.setStructField(structValue, name, value)
+	}, nil
+}
+
+// SetFieldJSON json encodes value and sets it into the string or []byte name field of obj,
+// for structured data(slices, structs, maps) kept in one text/json column. obj must be a pointer to struct
+func SetFieldJSON(obj interface{}, name string, value interface{}) error {
+	fieldValue, err := FieldValue(obj, name)
+	if err != nil {
+		return err
+	}
+	fieldType := fieldValue.Type()
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	switch {
+	case fieldType.Kind() == reflect.String:
+		return SetField(obj, name, string(b))
+	case isBytesType(fieldType):
+		return SetField(obj, name, b)
+	default:
+		return fmt.Errorf("cannot set json into %s field %s, want string or []byte", fieldType.String(), name)
+	}
+}
 
-	if !val.IsValid() {
-		//ignore all invalid val
+func (m *Mapper) setField(obj interface{}, name string, value interface{}) error {
+	structValue, err := ReflectValueSettable(obj)
+	if err != nil {
+		return err
+	}
+
+	return m.setStructField(structValue, name, value)
+}
+
+// ErrFieldNotSettable is returned when setting a field reflect cannot set, like
+// unexported fields or fields promoted through an unexported or nil embedded struct
+var ErrFieldNotSettable = errors.New("cannot set field value")
+
+// setStructField sets the name field of the addressable struct value structValue
+func (m *Mapper) setStructField(structValue reflect.Value, name string, value interface{}) error {
+	// Fetch the field reflect.Value, promoted fields through a nil embedded
+	// pointer are invalid instead of panicking
+	structField, found := structValue.Type().FieldByName(name)
+	var structFieldValue reflect.Value
+	var fieldErr error
+	if found {
+		structFieldValue, fieldErr = structValue.FieldByIndexErr(structField.Index)
+	}
+
+	if m.skipZero && (value == nil || reflect.ValueOf(value).IsZero()) {
+		//patch mode, zero val leaves the field unchanged
 		return nil
 	}
 
-	// Fetch the field reflect.Value
-	structValue := reflect.ValueOf(obj).Elem()
-	structFieldValue := structValue.FieldByName(name)
+	if value == nil {
+		//nil clears pointer fields, other fields ignore invalid val
+		if structFieldValue.IsValid() && structFieldValue.Kind() == reflect.Ptr && structFieldValue.CanSet() {
+			if !isSkipField(structField) {
+				structFieldValue.Set(reflect.Zero(structFieldValue.Type()))
+			}
+		}
+		return nil
+	}
 
-	if !structFieldValue.IsValid() {
+	if !found {
 		return fmt.Errorf("no such field: %s in obj", name)
 	}
 
-	// If obj field value is not settable an error is thrown
+	// If obj field value is not settable an error is thrown, this covers fields
+	// promoted from unexported or nil embedded structs as well
+	if fieldErr != nil {
+		return fmt.Errorf("%w: %s (%v)", ErrFieldNotSettable, name, fieldErr)
+	}
 	if !structFieldValue.CanSet() {
-		return fmt.Errorf("cannot set %s field value", name)
-	}
-
-	structFieldType := structFieldValue.Type()
-
-	if structFieldType != val.Type() {
-		//fmt.Println("name:", name, "v type:", val.Type().String())
-		switch structFieldType.Kind() {
-
-		case reflect.String:
-			switch val.Type().String() {
-			case "time.Time":
-				valTime := value.(time.Time)
-				val = reflect.ValueOf(TimeISOStr(valTime))
-				goto SETVALUE
-			case "[]uint8":
-				valUuid := value.([]uint8)
-				val = reflect.ValueOf(string(valUuid))
-				goto SETVALUE
-
-			case "[16]uint8":
-				uuid16 := value.([16]uint8)
-				uuidv := *(*uuid.UUID)(unsafe.Pointer(&uuid16))
-				val = reflect.ValueOf(uuidv.String())
-				goto SETVALUE
-
-			case "map[string]interface {}":
-				//json
-				b, err := json.Marshal(value)
-				if err != nil {
-					return err
-				}
-				val = reflect.ValueOf(string(b))
-				goto SETVALUE
+		return fmt.Errorf("%w: %s", ErrFieldNotSettable, name)
+	}
 
-			case "int32":
-				if WarnInt2StrInSetField {
-					fmt.Println("setfield to string warn:", name, val.Type().String())
-				}
-				v32 := value.(int32)
-				val = reflect.ValueOf(strconv.Itoa(int(v32)))
-				goto SETVALUE
-			case "int64":
-				usec := value.(int64)
-
-				if strings.Contains(name, "Time") || strings.Contains(name, "time") {
-					//time format, Number of microseconds since midnight
-					hours := usec / microsecondsPerHour
-					usec -= hours * microsecondsPerHour
-					minutes := usec / microsecondsPerMinute
-					usec -= minutes * microsecondsPerMinute
-					seconds := usec / microsecondsPerSecond
-					//usec -= seconds * microsecondsPerSecond
-
-					s := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
-					val = reflect.ValueOf(s)
-				} else {
-					s := strconv.FormatInt(usec, 10)
-					val = reflect.ValueOf(s)
-				}
-				goto SETVALUE
+	// Fields tagged `ygutil:"-"` are never written
+	if isSkipField(structField) {
+		return nil
+	}
 
+	if m.tagDirectives {
+		if converterName := structField.Tag.Get(TagKeyConvert); converterName != "" {
+			converter, ok := namedConverter(converterName)
+			if !ok {
+				return fmt.Errorf("%s: unknown converter %q", name, converterName)
 			}
-		case reflect.Int32:
-			switch val.Type().Kind() {
-			case reflect.Uint32:
-				valU32 := value.(uint32)
-				val = reflect.ValueOf(int32(valU32))
-				goto SETVALUE
-			case reflect.Int64:
-				valI64 := value.(int64)
-				val = reflect.ValueOf(int32(valI64))
-				goto SETVALUE
-			case reflect.Uint64:
-				valU64 := value.(uint64)
-				val = reflect.ValueOf(int32(valU64))
-				goto SETVALUE
+			converted, err := converter(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
 			}
-		case reflect.Uint32:
-			switch val.Type().Kind() {
-			case reflect.Int32:
-				valI32 := value.(int32)
-				val = reflect.ValueOf(uint32(valI32))
-				goto SETVALUE
-			case reflect.Int64:
-				valI64 := value.(int64)
-				val = reflect.ValueOf(uint32(valI64))
-				goto SETVALUE
-			case reflect.Uint64:
-				valU64 := value.(uint64)
-				val = reflect.ValueOf(uint32(valU64))
-				goto SETVALUE
+			if converted == nil {
+				return nil
+			}
+			value = converted
+		}
+
+		if scaleTag := structField.Tag.Get(TagKeyScale); scaleTag != "" && structField.Type.Kind() == reflect.String {
+			scale, err := strconv.Atoi(scaleTag)
+			if err != nil || scale < 0 {
+				return fmt.Errorf("%s: invalid scale %q", name, scaleTag)
+			}
+			if s, ok := formatScaled(value, scale); ok {
+				value = s
 			}
 		}
-		invalidTypeError := errors.New(name + ": value type didn't match obj field type " + structFieldType.String() + ":" + val.Type().String())
-		fmt.Println(name, invalidTypeError)
-		return invalidTypeError
 	}
-SETVALUE:
-	structFieldValue.Set(val)
+
+	return m.setValue(structFieldValue, structField, value)
+}
+
+// formatScaled formats the number value with scale decimal places, ok is false when value is not a number.
+// integers, json.Number, big.Int and big.Float are formatted exactly(halves rounded away from zero),
+// only float32/float64 values go through their binary value
+func formatScaled(value interface{}, scale int) (string, bool) {
+	if number, ok := value.(json.Number); ok {
+		r, ok := new(big.Rat).SetString(number.String())
+		if !ok {
+			return "", false
+		}
+		return r.FloatString(scale), true
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return "", false
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(val.Int()).FloatString(scale), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(val.Uint())).FloatString(scale), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', scale, 64), true
+	}
+
+	switch val.Type() {
+	case bigIntType:
+		b := val.Interface().(big.Int)
+		return new(big.Rat).SetInt(&b).FloatString(scale), true
+	case bigFloatType:
+		f := val.Interface().(big.Float)
+		if f.IsInf() {
+			return "", false
+		}
+		r, _ := f.Rat(nil)
+		return r.FloatString(scale), true
+	}
+	return "", false
+}
+
+// isSkipField reports whether field is tagged `ygutil:"-"`
+func isSkipField(field reflect.StructField) bool {
+	return tagValueName(field.Tag.Get(TagKeyYgutil)) == "-"
+}
+
+// setValue converts value to the type of the settable structFieldValue and sets it,
+// field is the struct field being set, its name is used in errors
+func (m *Mapper) setValue(structFieldValue reflect.Value, field reflect.StructField, value interface{}) error {
+	converted, err := m.convertValue(reflect.ValueOf(value), field, structFieldValue.Type())
+	if err != nil {
+		err = fmt.Errorf("%s: %w", field.Name, err)
+		if errors.Is(err, errTypeMismatch) {
+			m.logf("%s %v", field.Name, err)
+		}
+		return err
+	}
+
+	if converted.IsValid() {
+		if m.copyReferenceTypes {
+			converted = copyReferences(converted)
+		}
+		structFieldValue.Set(converted)
+	}
 	return nil
 }
 
+// copyReferences returns a copy of v not sharing data with it: slices, maps, pointers and big numbers
+// are copied, also when nested in elements, map values, array elements, pointer targets and the exported
+// fields of structs. unexported struct fields, interfaces, channels and funcs are kept as is
+func copyReferences(v reflect.Value) reflect.Value {
+	return copyReferencesSeen(v, map[seenPointer]reflect.Value{})
+}
+
+// seenPointer identifies a copied pointer, pointers of different types can share an address,
+// like a struct and its first field or zero size values
+type seenPointer struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+// copyReferencesSeen is copyReferences, seen maps the pointers copied so far to their copies,
+// so shared and cyclic pointers stay shared and cyclic in the copy
+func copyReferencesSeen(v reflect.Value, seen map[seenPointer]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := seenPointer{typ: v.Type(), addr: v.Pointer()}
+		if copied, ok := seen[key]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		seen[key] = copied
+		copied.Elem().Set(copyReferencesSeen(v.Elem(), seen))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyReferencesSeen(v.Index(i), seen))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyReferencesSeen(v.Index(i), seen))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), copyReferencesSeen(iter.Value(), seen))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		switch v.Type() {
+		case bigIntType:
+			b := v.Interface().(big.Int)
+			copied.Set(reflect.ValueOf(*new(big.Int).Set(&b)))
+			return copied
+		case bigFloatType:
+			f := v.Interface().(big.Float)
+			copied.Set(reflect.ValueOf(*new(big.Float).Copy(&f)))
+			return copied
+		}
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(copyReferencesSeen(v.Field(i), seen))
+			}
+		}
+		return copied
+	}
+	return v
+}
+
 // HasField checks if the provided field name is part of a struct. obj can whether
 // be a structure or pointer to structure.
 func HasField(obj interface{}, name string) (bool, error) {
@@ -235,7 +562,8 @@ func HasField(obj interface{}, name string) (bool, error) {
 }
 
 // Fields returns the struct fields names list. obj can whether
-// be a structure or pointer to structure.
+// be a structure or pointer to structure, or a map[string]interface{}
+// whose sorted keys are returned.
 func Fields(obj interface{}) ([]string, error) {
 	return fields(obj, false)
 }
@@ -255,6 +583,19 @@ func GetStructAllFieldNames(obj interface{}) []string {
 // GetStructAllFieldNamesAndJsonTag 得到一个struct里面所有的导出的字段名和对应的json tag名
 // fieldnamefirst:是否将字段名作为key,true:fieldname作为key,false:tagname作为key
 func GetStructAllFieldNamesAndJsonTag(obj interface{}, deep bool, fieldnamefirst bool) (map[string]string, error) {
+	return structFieldNamesAndTags(obj, "json", deep, fieldnamefirst, nil)
+}
+
+// GetStructFieldNamesWithDerivedTags 与GetStructAllFieldNamesAndJsonTag相同,但tagKey可指定,
+// 没有tag的字段使用derive(fieldName)作为tag名, derive为nil时使用ToSnakeCase
+func GetStructFieldNamesWithDerivedTags(obj interface{}, tagKey string, deep bool, fieldnamefirst bool, derive func(string) string) (map[string]string, error) {
+	if derive == nil {
+		derive = ToSnakeCase
+	}
+	return structFieldNamesAndTags(obj, tagKey, deep, fieldnamefirst, derive)
+}
+
+func structFieldNamesAndTags(obj interface{}, tagKey string, deep bool, fieldnamefirst bool, derive func(string) string) (map[string]string, error) {
 	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
 		return nil, errors.New("cannot use GetField on a non-struct interface")
 	}
@@ -263,41 +604,36 @@ func GetStructAllFieldNamesAndJsonTag(obj interface{}, deep bool, fieldnamefirst
 	objType := objValue.Type()
 	fieldsCount := objType.NumField()
 
-	allfieldAndJsons := make(map[string]string)
+	allfieldAndTags := make(map[string]string)
 
 	for i := 0; i < fieldsCount; i++ {
 		field := objType.Field(i)
 		if IsExportableField(field) {
 			if deep && field.Anonymous {
 				fieldValue := objValue.Field(i)
-				subFields, err := GetStructAllFieldNamesAndJsonTag(fieldValue.Interface(), deep, fieldnamefirst)
+				subFields, err := structFieldNamesAndTags(fieldValue.Interface(), tagKey, deep, fieldnamefirst, derive)
 				if err != nil {
 					return nil, fmt.Errorf("cannot get fields in %s: %s", field.Name, err.Error())
-				} else {
-					if fieldnamefirst {
-						for fieldname, jsontag := range subFields {
-							allfieldAndJsons[fieldname] = jsontag
-						}
-					} else {
-						for jsontag, fieldname := range subFields {
-							allfieldAndJsons[jsontag] = fieldname
-						}
-					}
+				}
+				for k, v := range subFields {
+					allfieldAndTags[k] = v
 				}
 			} else {
-				jsontag := field.Tag.Get("json")
-				before, _, _ := strings.Cut(jsontag, ",")
+				tagname := tagValueName(field.Tag.Get(tagKey))
+				if tagname == "" && derive != nil {
+					tagname = derive(field.Name)
+				}
 				if fieldnamefirst {
-					allfieldAndJsons[field.Name] = before
+					allfieldAndTags[field.Name] = tagname
 				} else {
-					allfieldAndJsons[before] = field.Name
+					allfieldAndTags[tagname] = field.Name
 				}
 
 			}
 		}
 	}
 
-	return allfieldAndJsons, nil
+	return allfieldAndTags, nil
 }
 
 // FieldsDeep returns "flattened" fields (fields from anonymous
@@ -306,7 +642,124 @@ func FieldsDeep(obj interface{}) ([]string, error) {
 	return fields(obj, true)
 }
 
+// NumExportableFields returns len of what Fields(deep false) or FieldsDeep(deep true) returns
+// without building the names, for pre-sizing maps and slices. it walks the fields the same way,
+// so it fails where FieldsDeep fails, like on nil or non-struct anonymous embeds.
+// a map[string]interface{} counts its keys
+func NumExportableFields(obj interface{}, deep bool) (int, error) {
+	if m, ok := obj.(map[string]interface{}); ok {
+		return len(m), nil
+	}
+
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return 0, errors.New("cannot use NumExportableFields on a non-struct interface")
+	}
+
+	return numExportableFields(ReflectValue(obj), deep)
+}
+
+// numExportableFields counts like fields counts names
+func numExportableFields(objValue reflect.Value, deep bool) (int, error) {
+	objType := objValue.Type()
+	n := 0
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+		if !deep || !field.Anonymous {
+			n++
+			continue
+		}
+
+		fieldValue := objValue.Field(i)
+		if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Struct {
+			if m, ok := fieldValue.Interface().(map[string]interface{}); ok {
+				n += len(m)
+				continue
+			}
+			return 0, fmt.Errorf("cannot get fields in %s: cannot use GetField on a non-struct interface", field.Name)
+		}
+		subCount, err := numExportableFields(fieldValue, deep)
+		if err != nil {
+			return 0, fmt.Errorf("cannot get fields in %s: %s", field.Name, err.Error())
+		}
+		n += subCount
+	}
+	return n, nil
+}
+
+// FieldsDeepOrdered returns the flattened fields like FieldsDeep in a guaranteed order:
+// depth first in declaration order, every exportable anonymous struct(or pointer to struct)
+// is expanded in place of the embed field. the order only depends on the type, so nil embedded
+// pointers are expanded as well. a name declared more than once follows the Go promotion rules
+// like reflect.Type.FieldByName: it is listed at the place of its shallowest declaration, and
+// left out when several embeds declare it at the same shallowest depth(ambiguous selector).
+// use it where the order must be stable, like csv headers or hashes
+func FieldsDeepOrdered(obj interface{}) ([]string, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use FieldsDeepOrdered on a non-struct interface")
+	}
+
+	objType := ReflectValue(obj).Type()
+	return fieldsOrdered(objType, objType, nil, map[reflect.Type]bool{objType: true}, nil), nil
+}
+
+// fieldsOrdered appends the names of typ(reached from rootType through index) in FieldsDeepOrdered
+// order, a name is only appended at the declaration rootType.FieldByName resolves it to
+func fieldsOrdered(rootType, typ reflect.Type, index []int, expanding map[reflect.Type]bool, allFields []string) []string {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && embedded.Kind() == reflect.Struct && !expanding[embedded] {
+			expanding[embedded] = true
+			allFields = fieldsOrdered(rootType, embedded, fieldIndex, expanding, allFields)
+			delete(expanding, embedded)
+			continue
+		}
+
+		if promoted, ok := rootType.FieldByName(field.Name); ok && equalIndex(promoted.Index, fieldIndex) {
+			allFields = append(allFields, field.Name)
+		}
+	}
+
+	return allFields
+}
+
+func equalIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func fields(obj interface{}, deep bool) ([]string, error) {
+	if m, ok := obj.(map[string]interface{}); ok {
+		//decoded json object, the keys are the fields
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys, nil
+	}
+
 	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
 		return nil, errors.New("cannot use GetField on a non-struct interface")
 	}
@@ -336,7 +789,8 @@ func fields(obj interface{}, deep bool) ([]string, error) {
 }
 
 // Items returns the field - value struct pairs as a map. obj can whether
-// be a structure or pointer to structure.
+// be a structure or pointer to structure, or a map[string]interface{}
+// which is returned as a copy.
 func Items(obj interface{}) (map[string]interface{}, error) {
 	return items(obj, false)
 }
@@ -348,6 +802,14 @@ func ItemsDeep(obj interface{}) (map[string]interface{}, error) {
 }
 
 func items(obj interface{}, deep bool) (map[string]interface{}, error) {
+	if m, ok := obj.(map[string]interface{}); ok {
+		allItems := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			allItems[k] = v
+		}
+		return allItems, nil
+	}
+
 	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
 		return nil, errors.New("cannot use GetField on a non-struct interface")
 	}
@@ -379,6 +841,81 @@ func items(obj interface{}, deep bool) (map[string]interface{}, error) {
 	return allItems, nil
 }
 
+// ItemsViaGetters returns the field - value pairs like Items, but unexported fields are
+// read through a getter method named Get + capitalized field name (like protobuf GetXxx)
+// and keyed by the capitalized name. only getters matching an unexported field are called,
+// unexported fields without such getter, or whose getter panics, are skipped.
+func ItemsViaGetters(obj interface{}) (map[string]interface{}, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use ItemsViaGetters on a non-struct interface")
+	}
+
+	objValue := ReflectValue(obj)
+	objType := objValue.Type()
+
+	// getters may have pointer receivers
+	objPtr := reflect.ValueOf(obj)
+	if objPtr.Kind() != reflect.Ptr {
+		objPtr = reflect.New(objType)
+		objPtr.Elem().Set(objValue)
+	}
+
+	allItems := make(map[string]interface{})
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		if IsExportableField(field) {
+			allItems[field.Name] = objValue.Field(i).Interface()
+			continue
+		}
+
+		runes := []rune(field.Name)
+		runes[0] = unicode.ToUpper(runes[0])
+		name := string(runes)
+		getter := objPtr.MethodByName("Get" + name)
+		if !getter.IsValid() || getter.Type().NumIn() != 0 || getter.Type().NumOut() != 1 {
+			continue
+		}
+		if value, ok := callGetter(getter); ok {
+			allItems[name] = value
+		}
+	}
+
+	return allItems, nil
+}
+
+// callGetter calls the getter method, ok is false when it panicked
+func callGetter(getter reflect.Value) (value interface{}, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	return getter.Call(nil)[0].Interface(), true
+}
+
+// EachField calls fn with the name and value of every exportable field of obj
+// in declaration order until fn returns false, without building a map like Items.
+// obj can whether be a structure or pointer to structure.
+func EachField(obj interface{}, fn func(name string, value interface{}) bool) error {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return errors.New("cannot use EachField on a non-struct interface")
+	}
+
+	objValue := ReflectValue(obj)
+	objType := objValue.Type()
+	fieldsCount := objType.NumField()
+
+	for i := 0; i < fieldsCount; i++ {
+		field := objType.Field(i)
+		if IsExportableField(field) && !fn(field.Name, objValue.Field(i).Interface()) {
+			break
+		}
+	}
+
+	return nil
+}
+
 // Tags lists the struct tag fields. obj can whether
 // be a structure or pointer to structure.
 func Tags(obj interface{}, key string) (map[string]string, error) {
@@ -423,9 +960,15 @@ func tags(obj interface{}, key string, deep bool) (map[string]string, error) {
 	return allTags, nil
 }
 
+// ReflectValue returns the reflect.Value of obj, pointers are dereferenced.
+// a nil obj returns the zero reflect.Value
 func ReflectValue(obj interface{}) reflect.Value {
 	var val reflect.Value
 
+	if obj == nil {
+		return val
+	}
+
 	if reflect.TypeOf(obj).Kind() == reflect.Ptr {
 		val = reflect.ValueOf(obj).Elem()
 	} else {
@@ -435,14 +978,41 @@ func ReflectValue(obj interface{}) reflect.Value {
 	return val
 }
 
+// ReflectValueSettable returns the addressable struct value obj points to,
+// obj must be a non nil pointer to struct
+func ReflectValueSettable(obj interface{}) (reflect.Value, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Ptr}) || reflect.TypeOf(obj).Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("obj must be a non nil pointer to struct")
+	}
+
+	return reflect.ValueOf(obj).Elem(), nil
+}
+
 func IsExportableField(field reflect.StructField) bool {
 	// PkgPath is empty for exported fields.
 	return field.PkgPath == ""
 }
 
+// IsExportedName reports whether name starts with an upper case letter, the field
+// name check of IsExportableField when only the name is at hand
+func IsExportedName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// hasValidType reports whether obj kind is one of types, nil obj and nil pointers are never valid
 func hasValidType(obj interface{}, types []reflect.Kind) bool {
+	if obj == nil {
+		return false
+	}
+
+	objType := reflect.TypeOf(obj)
+	if objType.Kind() == reflect.Ptr && reflect.ValueOf(obj).IsNil() {
+		return false
+	}
+
 	for _, t := range types {
-		if reflect.TypeOf(obj).Kind() == t {
+		if objType.Kind() == t {
 			return true
 		}
 	}
@@ -451,15 +1021,59 @@ func hasValidType(obj interface{}, types []reflect.Kind) bool {
 }
 
 func IsStruct(obj interface{}) bool {
+	if obj == nil {
+		return false
+	}
 	return reflect.TypeOf(obj).Kind() == reflect.Struct
 }
 
 func IsPointer(obj interface{}) bool {
+	if obj == nil {
+		return false
+	}
 	return reflect.TypeOf(obj).Kind() == reflect.Ptr
 }
 
+// GetStructName returns the unqualified type name of obj, pointers are dereferenced.
+// anonymous types and nil return ""
+func GetStructName(obj interface{}) string {
+	objType := derefType(obj)
+	if objType == nil {
+		return ""
+	}
+	return objType.Name()
+}
+
+// GetTypeFullName returns the package qualified type name of obj like "github.com/foo/bar.User",
+// pointers are dereferenced. predeclared types return their name, anonymous types and nil return ""
+func GetTypeFullName(obj interface{}) string {
+	objType := derefType(obj)
+	if objType == nil || objType.Name() == "" {
+		return ""
+	}
+	if objType.PkgPath() == "" {
+		return objType.Name()
+	}
+	return objType.PkgPath() + "." + objType.Name()
+}
+
+func derefType(obj interface{}) reflect.Type {
+	if obj == nil {
+		return nil
+	}
+	objType := reflect.TypeOf(obj)
+	for objType.Kind() == reflect.Ptr {
+		objType = objType.Elem()
+	}
+	return objType
+}
+
 // HasMethod 对象是否有此方法
 func HasMethod(obj interface{}, MethodName string) bool {
+	if obj == nil {
+		return false
+	}
+
 	ValueIface := reflect.ValueOf(obj)
 
 	// Check if the passed interface is a pointer
@@ -473,20 +1087,120 @@ func HasMethod(obj interface{}, MethodName string) bool {
 	return Method.IsValid()
 }
 
+// MethodInfo 方法的签名信息, In/Out为参数和返回值的类型名(不含receiver),
+// PointerReceiver表示只能通过指针调用
+type MethodInfo struct {
+	Name            string
+	NumIn           int
+	NumOut          int
+	In              []string
+	Out             []string
+	PointerReceiver bool
+}
+
+// MethodsInfo 返回对象(包含指针receiver的)导出方法的签名信息, 按方法名排序
+func MethodsInfo(obj interface{}) ([]MethodInfo, error) {
+	if obj == nil {
+		return nil, errors.New("cannot use MethodsInfo on nil")
+	}
+
+	valueType := reflect.TypeOf(obj)
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	ptrType := reflect.PointerTo(valueType)
+
+	infos := make([]MethodInfo, 0, ptrType.NumMethod())
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		method := ptrType.Method(i)
+		methodType := method.Type
+
+		info := MethodInfo{
+			Name:   method.Name,
+			NumIn:  methodType.NumIn() - 1,
+			NumOut: methodType.NumOut(),
+		}
+		for j := 1; j < methodType.NumIn(); j++ {
+			info.In = append(info.In, methodType.In(j).String())
+		}
+		for j := 0; j < methodType.NumOut(); j++ {
+			info.Out = append(info.Out, methodType.Out(j).String())
+		}
+		_, onValue := valueType.MethodByName(method.Name)
+		info.PointerReceiver = !onValue
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
 var EfieldNameCountNotEqualToFieldValues = errors.New("field name count not equal to field values")
 
 // SetFields 设置对象相应的值 obj.fieldNames0=fieldVals0, ...
-func SetFields(obj interface{}, fieldNames []string, fieldVals []interface{}) (err error) {
+// 出错时继续设置其余字段, 返回包含所有错误的*MultiError
+func SetFields(obj interface{}, fieldNames []string, fieldVals []interface{}) error {
+	return defaultMapper.SetFields(obj, fieldNames, fieldVals)
+}
+
+// SetFields sets obj fields like SetFields using the mapper configuration
+func (m *Mapper) SetFields(obj interface{}, fieldNames []string, fieldVals []interface{}) (err error) {
 	if len(fieldNames) > len(fieldVals) {
 		return EfieldNameCountNotEqualToFieldValues
 	}
 
+	var errs MultiError
 	for i, fieldName := range fieldNames {
-		errTmp := SetField(obj, fieldName, fieldVals[i])
-		if errTmp != nil {
-			err = errTmp
+		errs.add(fieldName, m.Set(obj, fieldName, fieldVals[i]))
+	}
+
+	return errs.errOrNil()
+}
+
+var cyclicTypeCache sync.Map
+
+// IsCyclicType reports whether the type of obj contains a recursive reference,
+// a field whose type (through pointers, slices, arrays and maps) eventually contains
+// a type which is already being walked, e.g. type Node struct{ Children []*Node }.
+// the result is cached per reflect.Type
+func IsCyclicType(obj interface{}) bool {
+	if obj == nil {
+		return false
+	}
+
+	objType := reflect.TypeOf(obj)
+	if cyclic, ok := cyclicTypeCache.Load(objType); ok {
+		return cyclic.(bool)
+	}
+
+	cyclic := hasTypeCycle(objType, make(map[reflect.Type]bool))
+	cyclicTypeCache.Store(objType, cyclic)
+
+	return cyclic
+}
+
+// hasTypeCycle walk t depth first, walking is true for types on the current path and
+// false for types already known to be acyclic
+func hasTypeCycle(t reflect.Type, walking map[reflect.Type]bool) bool {
+	if onPath, seen := walking[t]; seen {
+		return onPath
+	}
+
+	walking[t] = true
+	defer func() { walking[t] = false }()
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasTypeCycle(t.Elem(), walking)
+	case reflect.Map:
+		return hasTypeCycle(t.Key(), walking) || hasTypeCycle(t.Elem(), walking)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasTypeCycle(t.Field(i).Type, walking) {
+				return true
+			}
 		}
 	}
 
-	return
+	return false
 }
@@ -0,0 +1,157 @@
+package ygrpcgoutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+type OrderedBase struct {
+	ID   int
+	Name string
+}
+
+type OrderedAudit struct {
+	Name    string
+	Created int
+}
+
+type OrderedOther struct {
+	Created int
+}
+
+type OrderedDeep struct {
+	OrderedBase
+}
+
+func TestFieldsDeepOrdered(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want []string
+	}{
+		{
+			name: "embeds expanded in place",
+			obj: struct {
+				First string
+				OrderedBase
+				Last string
+			}{},
+			want: []string{"First", "ID", "Name", "Last"},
+		},
+		{
+			name: "outer field shadows later embed",
+			obj: struct {
+				Name string
+				OrderedBase
+			}{},
+			want: []string{"Name", "ID"},
+		},
+		{
+			name: "shallower embed wins over deeper earlier embed",
+			obj: struct {
+				OrderedDeep
+				Extra bool
+				OrderedAudit
+			}{},
+			want: []string{"ID", "Extra", "Name", "Created"},
+		},
+		{
+			name: "same depth collision is ambiguous",
+			obj: struct {
+				OrderedAudit
+				OrderedOther
+			}{},
+			want: []string{"Name"},
+		},
+		{
+			name: "nil embedded pointer expanded",
+			obj: &struct {
+				*OrderedBase
+				Extra bool
+			}{},
+			want: []string{"ID", "Name", "Extra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FieldsDeepOrdered(tt.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldsDeepOrdered() = %v, want %v", got, tt.want)
+			}
+			for _, name := range got {
+				if _, ok := ReflectValue(tt.obj).Type().FieldByName(name); !ok {
+					t.Errorf("%s is not resolvable by FieldByName", name)
+				}
+			}
+		})
+	}
+}
+
+type CountedInt int
+
+func TestNumExportableFieldsMatchesFieldsDeep(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+	}{
+		{name: "flat", obj: OrderedBase{}},
+		{name: "embedded struct", obj: struct {
+			OrderedBase
+			Extra bool
+		}{}},
+		{name: "non nil embedded pointer", obj: struct {
+			*OrderedBase
+			Extra bool
+		}{OrderedBase: &OrderedBase{}}},
+		{name: "nil embedded pointer", obj: struct {
+			*OrderedBase
+			Extra bool
+		}{}},
+		{name: "non-struct embed", obj: struct {
+			CountedInt
+			Extra bool
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, deep := range []bool{false, true} {
+				names, namesErr := fields(tt.obj, deep)
+				n, err := NumExportableFields(tt.obj, deep)
+				if (namesErr != nil) != (err != nil) {
+					t.Fatalf("deep %v: fields error %v, NumExportableFields error %v", deep, namesErr, err)
+				}
+				if err == nil && n != len(names) {
+					t.Errorf("deep %v: NumExportableFields() = %d, want %d", deep, n, len(names))
+				}
+			}
+		})
+	}
+}
+
+func TestGetFieldOrDefault(t *testing.T) {
+	type withPtr struct {
+		*OrderedBase
+		Extra string
+	}
+
+	if got := GetFieldOrDefault(withPtr{}, "Name", "def"); got != "def" {
+		t.Errorf("nil embedded pointer: GetFieldOrDefault() = %v, want def", got)
+	}
+	if got := GetFieldOrDefault(withPtr{OrderedBase: &OrderedBase{Name: "n"}}, "Name", "def"); got != "n" {
+		t.Errorf("GetFieldOrDefault() = %v, want n", got)
+	}
+	if got := GetFieldOrDefault(withPtr{}, "Missing", 1); got != 1 {
+		t.Errorf("missing field: GetFieldOrDefault() = %v, want 1", got)
+	}
+	if got := GetFieldOrDefault((*withPtr)(nil), "Extra", "def"); got != "def" {
+		t.Errorf("nil obj: GetFieldOrDefault() = %v, want def", got)
+	}
+	if got := GetFieldOrDefault(42, "Extra", "def"); got != "def" {
+		t.Errorf("non struct: GetFieldOrDefault() = %v, want def", got)
+	}
+}
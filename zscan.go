@@ -0,0 +1,145 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UnmappedColumnsError is returned by ScanMapStrict and strict NewStructFromRow when some row columns match no field
+type UnmappedColumnsError struct {
+	Columns []string
+}
+
+func (e *UnmappedColumnsError) Error() string {
+	return "unmapped columns: " + strings.Join(e.Columns, ", ")
+}
+
+// ScanMap 将数据库行row(列名=>值)设置到dst(struct指针)中, 列按tagKey的tag名匹配字段,
+// 没有tag的字段按字段名匹配. 使用SetField的所有类型转换(sql.NullXxx, []byte, uuid等),
+// 没有对应字段的列忽略. 字段经由nil的嵌入struct指针提升时会分配该指针.
+// 出错时继续设置其余字段, 返回包含所有错误的*MultiError
+func ScanMap(dst interface{}, row map[string]interface{}, tagKey string) error {
+	_, err := scanMap(dst, row, tagKey)
+	return err
+}
+
+// ScanMapStrict 与ScanMap相同, 但有没有对应字段的列时返回*UnmappedColumnsError
+func ScanMapStrict(dst interface{}, row map[string]interface{}, tagKey string) error {
+	unmapped, err := scanMap(dst, row, tagKey)
+	if err != nil {
+		return err
+	}
+	if len(unmapped) > 0 {
+		return &UnmappedColumnsError{Columns: unmapped}
+	}
+	return nil
+}
+
+func scanMap(dst interface{}, row map[string]interface{}, tagKey string) (unmapped []string, err error) {
+	structValue, err := ReflectValueSettable(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	columnFields := columnFieldsOf(structValue.Type(), tagKey)
+
+	var errs MultiError
+	for _, column := range sortedKeys(row) {
+		fieldName, ok := columnFields[column]
+		if !ok || column == "" || column == "-" {
+			if field, found := structValue.Type().FieldByName(column); found && IsExportableField(field) && tagValueName(field.Tag.Get(tagKey)) == "" {
+				fieldName = column
+			} else {
+				unmapped = append(unmapped, column)
+				continue
+			}
+		}
+
+		if row[column] != nil {
+			allocEmbeddedPtrs(structValue, fieldName)
+		}
+		if errTmp := defaultMapper.setStructField(structValue, fieldName, row[column]); errTmp != nil {
+			errs.add(fieldName, fmt.Errorf("column %s: %w", column, errTmp))
+		}
+	}
+
+	return unmapped, errs.errOrNil()
+}
+
+// columnFieldsOf returns the tag name => field name of the fields of the struct type t, fields of
+// anonymous structs and struct pointers included. it works on the type, so nil embedded pointers
+// of a freshly allocated struct don't hide their fields
+func columnFieldsOf(t reflect.Type, tagKey string) map[string]string {
+	columnFields := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+		if field.Anonymous && derefPtrType(field.Type).Kind() == reflect.Struct {
+			for column, name := range columnFieldsOf(derefPtrType(field.Type), tagKey) {
+				columnFields[column] = name
+			}
+			continue
+		}
+		columnFields[tagValueName(field.Tag.Get(tagKey))] = field.Name
+	}
+	return columnFields
+}
+
+// allocEmbeddedPtrs allocates the nil embedded struct pointers the name field of structValue
+// is promoted through, so it can be set. unsettable embeds are left for setStructField to report
+func allocEmbeddedPtrs(structValue reflect.Value, name string) {
+	field, ok := structValue.Type().FieldByName(name)
+	if !ok {
+		return
+	}
+	v := structValue
+	for _, i := range field.Index[:len(field.Index)-1] {
+		v = v.Field(i)
+		if v.Kind() != reflect.Ptr {
+			continue
+		}
+		if v.IsNil() {
+			if !v.CanSet() {
+				return
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+}
+
+// NewStructFromRow 分配一个与proto(struct或struct指针)相同类型的新struct, 将values按columns的列名
+// 用ScanMap的规则设置进去, 返回新struct的指针. 用于通用查询结果的逐行构造, columns与values长度必须相同,
+// 列名重复时返回错误. 没有对应字段的列在strict为false时忽略, 为true时返回*UnmappedColumnsError.
+// 设置出错时返回包含所有错误的*MultiError
+func NewStructFromRow(proto interface{}, columns []string, values []interface{}, tagKey string, strict bool) (interface{}, error) {
+	protoType := derefType(proto)
+	if protoType == nil || protoType.Kind() != reflect.Struct {
+		return nil, errors.New("proto must be a struct or pointer to struct")
+	}
+	if len(columns) != len(values) {
+		return nil, fmt.Errorf("%d columns but %d values", len(columns), len(values))
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if _, ok := row[column]; ok {
+			return nil, fmt.Errorf("duplicate column %s", column)
+		}
+		row[column] = values[i]
+	}
+
+	obj := reflect.New(protoType).Interface()
+	unmapped, err := scanMap(obj, row, tagKey)
+	if err != nil {
+		return nil, err
+	}
+	if strict && len(unmapped) > 0 {
+		return nil, &UnmappedColumnsError{Columns: unmapped}
+	}
+	return obj, nil
+}
@@ -0,0 +1,58 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewStructFromRow(t *testing.T) {
+	type user struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	obj, err := NewStructFromRow(user{}, []string{"id", "name", "extra"}, []interface{}{int64(1), "bob", 2}, "db", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := obj.(*user); u.ID != 1 || u.Name != "bob" {
+		t.Errorf("NewStructFromRow = %+v", *u)
+	}
+
+	_, err = NewStructFromRow(user{}, []string{"id", "name", "extra"}, []interface{}{int64(1), "bob", 2}, "db", true)
+	var unmapped *UnmappedColumnsError
+	if !errors.As(err, &unmapped) || len(unmapped.Columns) != 1 || unmapped.Columns[0] != "extra" {
+		t.Errorf("strict NewStructFromRow error = %v, want unmapped column extra", err)
+	}
+
+	if _, err = NewStructFromRow(user{}, []string{"name", "name"}, []interface{}{"a", "b"}, "db", false); err == nil {
+		t.Errorf("NewStructFromRow with duplicate columns succeeded")
+	}
+}
+
+type RowBase struct {
+	ID int64 `db:"id"`
+}
+
+func TestNewStructFromRowEmbeddedPointer(t *testing.T) {
+	type model struct {
+		*RowBase
+		Name string `db:"name"`
+	}
+
+	obj, err := NewStructFromRow(model{}, []string{"id", "name"}, []interface{}{int64(5), "n"}, "db", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := obj.(*model); m.RowBase == nil || m.ID != 5 || m.Name != "n" {
+		t.Errorf("NewStructFromRow = %+v", *m)
+	}
+
+	var m model
+	if err := ScanMap(&m, map[string]interface{}{"name": "x", "id": nil}, "db"); err != nil {
+		t.Fatal(err)
+	}
+	if m.RowBase != nil || m.Name != "x" {
+		t.Errorf("ScanMap with NULL id = %+v, want nil RowBase", m)
+	}
+}
@@ -0,0 +1,225 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagValueName return the name part of a tag value, "name,omitempty" => "name",
+// unlike TagName a "-" tag is returned as is so callers can tell ignored fields apart
+func tagValueName(tag string) string {
+	before, _, _ := strings.Cut(tag, ",")
+	return before
+}
+
+// TagInfo is a parsed tag value, `json:"name,omitempty,string"` => Name "name",
+// Options ["omitempty", "string"], Raw "name,omitempty,string"
+type TagInfo struct {
+	Name    string
+	Options []string
+	Raw     string
+}
+
+// Has reports whether opt is one of the tag options
+func (t TagInfo) Has(opt string) bool {
+	for _, o := range t.Options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTag parses a tag value into a TagInfo
+func ParseTag(tag string) TagInfo {
+	info := TagInfo{Name: tagValueName(tag), Raw: tag}
+	if _, options, found := strings.Cut(tag, ","); found {
+		info.Options = strings.Split(options, ",")
+	}
+	return info
+}
+
+// FieldTagInfo returns the parsed key tag of the provided obj field, a field without
+// such tag returns an empty TagInfo. obj can whether be a structure or pointer to structure,
+// fields promoted from anonymous structs are found as well.
+func FieldTagInfo(obj interface{}, fieldName, key string) (TagInfo, error) {
+	tag, err := GetRawFieldTag(obj, fieldName)
+	if err != nil {
+		return TagInfo{}, err
+	}
+
+	return ParseTag(tag.Get(key)), nil
+}
+
+// TagName returns the name part of the key tag of field without options,
+// `json:"name,omitempty"` => "name", a "-" tag returns ""
+func TagName(field reflect.StructField, key string) string {
+	name := tagValueName(field.Tag.Get(key))
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// JSONTagName returns TagName(field, "json")
+func JSONTagName(field reflect.StructField) string {
+	return TagName(field, "json")
+}
+
+// JSONTagNameOf returns the json tag name of the provided obj field like JSONTagName.
+// obj can whether be a structure or pointer to structure.
+func JSONTagNameOf(obj interface{}, fieldName string) (string, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return "", errors.New("cannot use JSONTagNameOf on a non-struct interface")
+	}
+
+	field, ok := ReflectValue(obj).Type().FieldByName(fieldName)
+	if !ok {
+		return "", fmt.Errorf("no such field: %s in obj", fieldName)
+	}
+
+	if !IsExportableField(field) {
+		return "", errors.New("cannot JSONTagNameOf on a non-exported struct field")
+	}
+
+	return JSONTagName(field), nil
+}
+
+// ValidateTags 检查obj所有导出字段都有非空的tagKey tag, requireUnique为true时还检查tag名不能重复
+// 没有tag的匿名嵌入struct会展开检查其字段, tag为"-"的字段视为明确忽略
+// 返回的*MultiError包含所有不符合的字段
+func ValidateTags(obj interface{}, tagKey string, requireUnique bool) error {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return errors.New("cannot use ValidateTags on a non-struct interface")
+	}
+
+	var errs MultiError
+	used := make(map[string]string)
+	validateTags(ReflectValue(obj).Type(), tagKey, requireUnique, used, &errs)
+
+	return errs.errOrNil()
+}
+
+func validateTags(objType reflect.Type, tagKey string, requireUnique bool, used map[string]string, errs *MultiError) {
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+
+		tagname := tagValueName(field.Tag.Get(tagKey))
+		if tagname == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateTags(field.Type, tagKey, requireUnique, used, errs)
+			continue
+		}
+
+		switch {
+		case tagname == "":
+			errs.add(field.Name, fmt.Errorf("field %s has no %s tag", field.Name, tagKey))
+		case tagname == "-":
+		case requireUnique:
+			if other, ok := used[tagname]; ok {
+				errs.add(field.Name, fmt.Errorf("%s tag %q used by both %s and %s", tagKey, tagname, other, field.Name))
+			} else {
+				used[tagname] = field.Name
+			}
+		}
+	}
+}
+
+// GetRawFieldTag returns the whole struct tag of the provided obj field, so
+// callers can Get/Lookup any key. obj can whether be a structure or pointer
+// to structure, fields promoted from anonymous structs are found as well.
+func GetRawFieldTag(obj interface{}, fieldName string) (reflect.StructTag, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return "", errors.New("cannot use GetRawFieldTag on a non-struct interface")
+	}
+
+	objType := ReflectValue(obj).Type()
+
+	field, ok := objType.FieldByName(fieldName)
+	if !ok {
+		return "", fmt.Errorf("no such field: %s in obj", fieldName)
+	}
+
+	if !IsExportableField(field) {
+		return "", errors.New("cannot GetRawFieldTag on a non-exported struct field")
+	}
+
+	return field.Tag, nil
+}
+
+// HasTag reports whether the provided obj field declares a tagKey tag, a present but
+// empty tag like `db:""` is declared, unlike GetFieldTag which returns "" for both.
+// obj can whether be a structure or pointer to structure.
+func HasTag(obj interface{}, fieldName, tagKey string) (bool, error) {
+	tag, err := GetRawFieldTag(obj, fieldName)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := tag.Lookup(tagKey)
+	return ok, nil
+}
+
+// GetFieldTagsMulti returns the tag values of keys for the provided obj field,
+// resolving the field once. keys the field has no tag for map to "".
+func GetFieldTagsMulti(obj interface{}, fieldName string, keys ...string) (map[string]string, error) {
+	tag, err := GetRawFieldTag(obj, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		values[key] = tag.Get(key)
+	}
+
+	return values, nil
+}
+
+// FieldByTag returns the exportable field of obj whose tagKey tag name(options after "," ignored)
+// is tagValue, found is false when no field has it. obj can whether be a structure or pointer to structure.
+func FieldByTag(obj interface{}, tagKey, tagValue string) (field reflect.StructField, found bool, err error) {
+	return fieldByTag(obj, tagKey, tagValue, false)
+}
+
+// FieldByTagDeep is FieldByTag which also searches the fields of anonymous structs without
+// tagKey tag, the returned field Index is the index path from obj
+func FieldByTagDeep(obj interface{}, tagKey, tagValue string) (field reflect.StructField, found bool, err error) {
+	return fieldByTag(obj, tagKey, tagValue, true)
+}
+
+func fieldByTag(obj interface{}, tagKey, tagValue string, deep bool) (reflect.StructField, bool, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return reflect.StructField{}, false, errors.New("cannot use FieldByTag on a non-struct interface")
+	}
+
+	field, found := findFieldByTag(ReflectValue(obj).Type(), tagKey, tagValue, deep, nil)
+	return field, found, nil
+}
+
+func findFieldByTag(objType reflect.Type, tagKey, tagValue string, deep bool, index []int) (reflect.StructField, bool) {
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+
+		tagname := tagValueName(field.Tag.Get(tagKey))
+		if tagname == tagValue && tagValue != "" {
+			field.Index = append(append([]int{}, index...), i)
+			return field, true
+		}
+
+		if deep && tagname == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if found, ok := findFieldByTag(field.Type, tagKey, tagValue, deep, append(index, i)); ok {
+				return found, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,161 @@
+package ygrpcgoutil
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timestampPkgPath is the package of protobuf's Timestamp, it is matched by name
+// so this package doesn't depend on protobuf
+const timestampPkgPath = "google.golang.org/protobuf/types/known/timestamppb"
+
+// IsTimeField reports whether field is a time.Time, *time.Time or *timestamppb.Timestamp
+func IsTimeField(field reflect.StructField) bool {
+	return isTimeType(field.Type)
+}
+
+func isTimeType(typ reflect.Type) bool {
+	if typ == timeType {
+		return true
+	}
+	if typ.Kind() != reflect.Ptr {
+		return false
+	}
+	elem := typ.Elem()
+	return elem == timeType || (elem.PkgPath() == timestampPkgPath && elem.Name() == "Timestamp")
+}
+
+// TimeFieldNames returns the names of the time fields(see IsTimeField) of obj in declaration order,
+// fields of anonymous structs are included like FieldsDeep
+func TimeFieldNames(obj interface{}) ([]string, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use TimeFieldNames on a non-struct interface")
+	}
+
+	names, err := FieldsDeep(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	objType := ReflectValue(obj).Type()
+	var timeNames []string
+	for _, name := range names {
+		if field, ok := objType.FieldByName(name); ok && IsTimeField(field) {
+			timeNames = append(timeNames, name)
+		}
+	}
+
+	return timeNames, nil
+}
+
+// WalkFunc is called by WalkStruct for every exportable field, path is the dotted
+// path of the field from obj (fields of anonymous structs use the promoted name).
+// returning an error stops the walk and is returned by WalkStruct
+type WalkFunc func(path string, field reflect.StructField, value reflect.Value) error
+
+// WalkStruct calls visit for every exportable field of obj depth first in declaration order.
+// struct typed fields (anonymous or named) are visited and then descended, except struct values
+// with unexported state like time.Time, big.Int or netip.Addr,
+// pointers are not followed. obj can whether be a structure or pointer to structure,
+// when obj is a pointer the visited values are settable.
+func WalkStruct(obj interface{}, visit WalkFunc) error {
+	return WalkStructCtx(context.Background(), obj, visit)
+}
+
+// WalkStructCtx is WalkStruct which checks ctx before every field and returns ctx.Err()
+// as soon as ctx is canceled or its deadline is exceeded
+func WalkStructCtx(ctx context.Context, obj interface{}, visit WalkFunc) error {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return errors.New("cannot use WalkStruct on a non-struct interface")
+	}
+
+	return walkStruct(ctx, ReflectValue(obj), "", visit)
+}
+
+func walkStruct(ctx context.Context, objValue reflect.Value, prefix string, visit WalkFunc) error {
+	objType := objValue.Type()
+
+	for i := 0; i < objType.NumField(); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		field := objType.Field(i)
+		if !IsExportableField(field) {
+			continue
+		}
+
+		fieldValue := objValue.Field(i)
+		path := prefix + field.Name
+		if err := visit(path, field, fieldValue); err != nil {
+			return err
+		}
+
+		if isLeafField(field) {
+			continue
+		}
+
+		subPrefix := path + "."
+		if field.Anonymous {
+			subPrefix = prefix
+		}
+		if err := walkStruct(ctx, fieldValue, subPrefix, visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LeafPaths returns the dotted path of every non-struct field of obj like
+// ["ID", "Address.City", "Address.Zip", "Tags"], in WalkStruct order.
+// slices, maps, pointers and struct values like time.Time or netip.Addr are leaves, nested structs are descended.
+func LeafPaths(obj interface{}) ([]string, error) {
+	var paths []string
+	err := WalkStruct(obj, func(path string, field reflect.StructField, value reflect.Value) error {
+		if isLeafField(field) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// FieldPath is a leaf field of DeepFieldsWithPaths, Name is the field name and Path
+// its dotted path from the walked struct like "Address.City"
+type FieldPath struct {
+	Name string
+	Path string
+}
+
+// DeepFieldsWithPaths returns the name and dotted path of every leaf field of obj, for labels
+// built from the name and values bound by the path. the order is the LeafPaths order, depth first
+// in declaration order with nested and anonymous structs expanded in place, fields of anonymous
+// structs have promoted paths. obj can whether be a structure or pointer to structure.
+func DeepFieldsWithPaths(obj interface{}) ([]FieldPath, error) {
+	var fieldPaths []FieldPath
+	err := WalkStruct(obj, func(path string, field reflect.StructField, value reflect.Value) error {
+		if isLeafField(field) {
+			fieldPaths = append(fieldPaths, FieldPath{Name: field.Name, Path: path})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldPaths, nil
+}
+
+// isLeafField reports whether WalkStruct does not descend field
+func isLeafField(field reflect.StructField) bool {
+	return !isNestedStructType(field.Type)
+}
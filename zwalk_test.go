@@ -0,0 +1,46 @@
+package ygrpcgoutil
+
+import (
+	"math/big"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+type walkHost struct {
+	Name  string
+	IP    netip.Addr
+	Price big.Int
+	Addr  struct {
+		City string
+	}
+}
+
+func TestLeafPathsOpaqueStructs(t *testing.T) {
+	paths, err := LeafPaths(walkHost{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Name", "IP", "Price", "Addr.City"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("LeafPaths() = %v, want %v", paths, want)
+	}
+
+	a := walkHost{Name: "h", IP: netip.MustParseAddr("10.0.0.1")}
+	b := walkHost{Name: "h", IP: netip.MustParseAddr("10.0.0.2")}
+	ops, err := JSONPatch(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 || ops[0]["path"] != "/IP" {
+		t.Errorf("JSONPatch() = %v, want one op for /IP", ops)
+	}
+
+	zero, err := ZeroFields(walkHost{Name: "h"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"IP", "Price", "Addr.City"}; !reflect.DeepEqual(zero, want) {
+		t.Errorf("ZeroFields() = %v, want %v", zero, want)
+	}
+}
@@ -0,0 +1,85 @@
+package ygrpcgoutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ZeroFieldsWhere sets every field of obj for which pred returns true to its zero value, like
+// fields tagged `sensitive:"true"` before archiving. fields of anonymous structs(and non nil
+// pointers to struct) pred doesn't match are checked as well. matched fields that can not be set
+// (unexported ones) are skipped, the other fields are still zeroed and the skipped ones are returned
+// as a *MultiError of ErrFieldNotSettable errors. obj must be a pointer to struct
+func ZeroFieldsWhere(obj interface{}, pred func(reflect.StructField) bool) error {
+	structValue, err := ReflectValueSettable(obj)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+	zeroFieldsWhere(structValue, pred, &errs)
+
+	return errs.errOrNil()
+}
+
+func zeroFieldsWhere(structValue reflect.Value, pred func(reflect.StructField) bool, errs *MultiError) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if pred(field) {
+			if fieldValue.CanSet() {
+				fieldValue.Set(reflect.Zero(field.Type))
+			} else {
+				errs.add(field.Name, fmt.Errorf("%w: %s", ErrFieldNotSettable, field.Name))
+			}
+			continue
+		}
+
+		if !field.Anonymous {
+			continue
+		}
+		if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() == reflect.Struct {
+			zeroFieldsWhere(fieldValue, pred, errs)
+		}
+	}
+}
+
+// ZeroFields returns the names of the exportable fields of obj holding their zero value in
+// declaration order, like fields a mapping step didn't populate. with deep the dotted
+// LeafPaths of the zero leaf fields are returned instead, nested and anonymous structs are descended.
+// obj can whether be a structure or pointer to structure.
+func ZeroFields(obj interface{}, deep bool) ([]string, error) {
+	if !hasValidType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
+		return nil, errors.New("cannot use ZeroFields on a non-struct interface")
+	}
+
+	var zeroNames []string
+	if deep {
+		err := WalkStruct(obj, func(path string, field reflect.StructField, value reflect.Value) error {
+			if isLeafField(field) && value.IsZero() {
+				zeroNames = append(zeroNames, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return zeroNames, nil
+	}
+
+	objValue := ReflectValue(obj)
+	objType := objValue.Type()
+	for i := 0; i < objType.NumField(); i++ {
+		if IsExportableField(objType.Field(i)) && objValue.Field(i).IsZero() {
+			zeroNames = append(zeroNames, objType.Field(i).Name)
+		}
+	}
+
+	return zeroNames, nil
+}